@@ -3,48 +3,116 @@ package inject
 import (
 	"fmt"
 	"reflect"
+	"sort"
+	"strings"
 )
 
 type autoProvider struct {
 	constructor interface{}
+	// names of the definitions to resolve by name, by argument index
+	names map[int]string
+	// optional argument indexes
+	optional map[int]bool
 }
 
-// NewAutoProvider specifies how to construct a value given its constructor function.
-// Argument values are auto-resolved by type.
-func NewAutoProvider(constructor interface{}) Provider {
-	fnValue := reflect.ValueOf(constructor)
-	if fnValue.Kind() != reflect.Func {
-		panic("constructor is not a function")
+// AutoProviderOption configures how an auto-provider resolves its constructor arguments
+type AutoProviderOption func(*autoProvider)
+
+// NamedArg resolves the constructor argument at the specified index by definition name, instead of by type
+func NamedArg(index int, name string) AutoProviderOption {
+	return func(p *autoProvider) {
+		p.names[index] = name
 	}
+}
 
-	fnType := reflect.TypeOf(constructor)
-	if fnType.NumOut() != 1 {
-		panic("constructor must have exactly 1 return value")
+// OptionalArg resolves the constructor argument at the specified index to its zero value,
+// instead of failing, when no defined pointer can supply it
+func OptionalArg(index int) AutoProviderOption {
+	return func(p *autoProvider) {
+		p.optional[index] = true
 	}
+}
 
-	return autoProvider{
+// NewAutoProvider specifies how to construct a value given its constructor function.
+// Argument values are auto-resolved by type, unless otherwise specified by options.
+// Variadic and slice arguments collect every defined pointer assignable to the element type,
+// unless a pointer to the slice type itself is defined.
+// Arguments of type Lazy[T] (or any func() T) are resolved when first called,
+// unless a pointer to the function type itself is defined.
+// The constructor may optionally return an error as its second return value.
+func NewAutoProvider(constructor interface{}, opts ...AutoProviderOption) Provider {
+	validateConstructor(constructor)
+
+	p := autoProvider{
 		constructor: constructor,
+		names:       make(map[int]string),
+		optional:    make(map[int]bool),
+	}
+	for _, opt := range opts {
+		opt(&p)
 	}
+
+	argCount := reflect.TypeOf(constructor).NumIn()
+	for i := range p.names {
+		if i < 0 || i >= argCount {
+			panic(fmt.Sprintf("named argument index (%d) must be less than the number of constructor arguments (%d)", i, argCount))
+		}
+	}
+	for i := range p.optional {
+		if i < 0 || i >= argCount {
+			panic(fmt.Sprintf("optional argument index (%d) must be less than the number of constructor arguments (%d)", i, argCount))
+		}
+	}
+
+	return p
 }
 
 // Provide returns the result of executing the constructor with argument values resolved by type from a dependency graph
-func (p autoProvider) Provide(g Graph) reflect.Value {
-	fnType := reflect.TypeOf(p.constructor)
+func (p autoProvider) Provide(g Graph) (reflect.Value, error) {
+	lazy := newDeferral(g)
+
+	deps := p.Dependencies()
+	args := make([]reflect.Value, len(deps), len(deps))
+	err := autoResolveArgs(g, lazy, deps, args, 0)
+	if err != nil {
+		return reflect.Value{}, err
+	}
+
+	return callConstructor(p.constructor, spreadVariadic(reflect.TypeOf(p.constructor), args))
+}
+
+// autoResolveArgs resolves the arguments of a constructor from their dependencies, starting at the specified index
+func autoResolveArgs(g Graph, lazy *deferral, deps []Dependency, args []reflect.Value, from int) error {
+	for i := from; i < len(deps); i++ {
+		dep := deps[i]
+		if resolvesLazily(g, dep) {
+			target := lazyDependency(dep)
+			args[i] = lazy.thunk(dep.Type, func(g Graph) (reflect.Value, error) {
+				return resolveArg(g, target)
+			})
+			continue
+		}
 
-	argCount := fnType.NumIn()
-	args := make([]reflect.Value, argCount, argCount)
-	for i := 0; i < argCount; i++ {
-		argType := fnType.In(i)
-		values := g.ResolveByType(argType)
-		if len(values) > 1 {
-			panic(fmt.Sprintf("more than one defined pointer is assignable to the provider argument %d of type (%v)", i, argType))
-		} else if len(values) == 0 {
-			panic(fmt.Sprintf("no defined pointer is assignable to the provider argument %d of type (%v)", i, argType))
+		arg, err := resolveArg(g, dep)
+		if err != nil {
+			return fmt.Errorf("failed to resolve provider argument %d: %w", i, err)
 		}
-		args[i] = values[0]
+		args[i] = arg
 	}
+	return nil
+}
 
-	return reflect.ValueOf(p.constructor).Call(args)[0]
+// spreadVariadic spreads the last argument value (a slice) over the variadic argument of a constructor
+func spreadVariadic(fnType reflect.Type, args []reflect.Value) []reflect.Value {
+	if !fnType.IsVariadic() {
+		return args
+	}
+	last := args[len(args)-1]
+	args = args[:len(args)-1]
+	for i := 0; i < last.Len(); i++ {
+		args = append(args, last.Index(i))
+	}
+	return args
 }
 
 // Type returns the type of value to expect from Provide
@@ -52,9 +120,93 @@ func (p autoProvider) ReturnType() reflect.Type {
 	return reflect.TypeOf(p.constructor).Out(0)
 }
 
+// Dependencies returns the argument types of the constructor, in argument order
+func (p autoProvider) Dependencies() []Dependency {
+	fnType := reflect.TypeOf(p.constructor)
+	deps := make([]Dependency, fnType.NumIn(), fnType.NumIn())
+	for i := range deps {
+		inType := fnType.In(i)
+		deps[i] = Dependency{
+			Type:     inType,
+			Name:     p.names[i],
+			Multi:    inType.Kind() == reflect.Slice,
+			Lazy:     isLazyType(inType),
+			Optional: p.optional[i],
+		}
+	}
+	return deps
+}
+
+// resolveArg resolves an auto-resolved argument, by name or by type
+func resolveArg(g Graph, dep Dependency) (reflect.Value, error) {
+	if dep.Optional && len(candidates(g, dep)) == 0 {
+		return reflect.Zero(dep.Type), nil
+	}
+	if dep.Name == "" {
+		if dep.Multi && len(g.DefinitionsByType(dep.Type)) == 0 {
+			return resolveMulti(g, dep.Type)
+		}
+		return resolveOne(g, dep.Type, dep.Assignable)
+	}
+
+	def, err := definitionNamed(g, dep.Name)
+	if err != nil {
+		return reflect.Value{}, err
+	}
+	arg, err := g.TryResolve(def.Ptr())
+	if err != nil {
+		return reflect.Value{}, err
+	}
+	return convertArg(arg, dep.Type)
+}
+
+// resolveMulti resolves every defined pointer assignable to the element type of a slice type
+// and returns a slice of the resolved values
+func resolveMulti(g Graph, sliceType reflect.Type) (reflect.Value, error) {
+	values, err := g.TryResolveByAssignableType(sliceType.Elem())
+	if err != nil {
+		return reflect.Value{}, err
+	}
+	return reflect.Append(reflect.MakeSlice(sliceType, 0, len(values)), values...), nil
+}
+
+func (p autoProvider) kind() string {
+	return "auto"
+}
+
+func (p autoProvider) constructorType() reflect.Type {
+	return reflect.TypeOf(p.constructor)
+}
+
 // String returns a multiline string representation of the autoProvider
 func (p autoProvider) String() string {
-	return fmt.Sprintf("&autoProvider{\n%s\n}",
-		indent(fmt.Sprintf("constructor: %s", reflect.TypeOf(p.constructor)), 1),
-	)
+	fields := []string{indent(fmt.Sprintf("constructor: %s", reflect.TypeOf(p.constructor)), 1)}
+	if len(p.names) > 0 {
+		fields = append(fields, indent(fmt.Sprintf("names: %s", p.fmtNames()), 1))
+	}
+	if len(p.optional) > 0 {
+		fields = append(fields, indent(fmt.Sprintf("optional: %s", p.fmtOptional()), 1))
+	}
+	return fmt.Sprintf("&autoProvider{\n%s\n}", strings.Join(fields, ",\n"))
+}
+
+func (p autoProvider) fmtNames() string {
+	m := make(map[string]string, len(p.names))
+	for i, name := range p.names {
+		m[fmt.Sprint(i)] = fmt.Sprintf("%q", name)
+	}
+	return mapString(m)
+}
+
+func (p autoProvider) fmtOptional() string {
+	indexes := make([]int, 0, len(p.optional))
+	for i := range p.optional {
+		indexes = append(indexes, i)
+	}
+	sort.Ints(indexes)
+	a := make([]string, len(indexes), len(indexes))
+	for j, i := range indexes {
+		a[j] = fmt.Sprint(i)
+	}
+	return arrayString(a)
 }
@@ -0,0 +1,49 @@
+package inject
+
+import (
+	"fmt"
+	"reflect"
+)
+
+var errorType = reflect.TypeOf((*error)(nil)).Elem()
+
+// validateConstructor panics if the constructor is not a function that returns a value, or a value and an error
+func validateConstructor(constructor interface{}) {
+	fnValue := reflect.ValueOf(constructor)
+	if fnValue.Kind() != reflect.Func {
+		panic(fmt.Sprintf("constructor (%v) is not a function, found %v", fnValue, fnValue.Kind()))
+	}
+
+	fnType := fnValue.Type()
+	switch fnType.NumOut() {
+	case 1:
+		return
+	case 2:
+		if fnType.Out(1) == errorType {
+			return
+		}
+	}
+	panic(fmt.Sprintf("constructor must return a value or a value and an error, found %v", fnType))
+}
+
+// callConstructor calls the constructor with the supplied args and returns its value,
+// or the error returned by the constructor, if any
+func callConstructor(constructor interface{}, args []reflect.Value) (reflect.Value, error) {
+	results := reflect.ValueOf(constructor).Call(args)
+	if len(results) > 1 && !results[1].IsNil() {
+		return reflect.Value{}, fmt.Errorf("provider constructor (%s) failed: %w", reflect.TypeOf(constructor), results[1].Interface().(error))
+	}
+	return results[0], nil
+}
+
+// convertArg returns the argument value, assigned or converted to the constructor argument type
+func convertArg(arg reflect.Value, inType reflect.Type) (reflect.Value, error) {
+	argType := arg.Type()
+	if argType.AssignableTo(inType) {
+		return arg, nil
+	}
+	if !argType.ConvertibleTo(inType) {
+		return reflect.Value{}, ErrTypeMismatch{From: argType, To: inType}
+	}
+	return arg.Convert(inType), nil
+}
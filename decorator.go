@@ -0,0 +1,110 @@
+package inject
+
+import (
+	"fmt"
+	"reflect"
+)
+
+type decorator struct {
+	targetType reflect.Type
+	fn         interface{}
+}
+
+// Decorate registers a decorator that wraps the value of every definition whose pointer has the exact target type,
+// like a caching or metrics layer. The decorator must be a function that takes a value of the target type, followed
+// by any number of arguments auto-resolved like those of NewAutoProvider, and returns a value assignable to the
+// target type (and optionally an error).
+// Decorators are applied to values resolved after they are registered, after the provider constructs the value and
+// before it is initialized. They are applied in the order they were registered, with decorators of parents first.
+func (g *graph) Decorate(targetType reflect.Type, fn interface{}) {
+	validateConstructor(fn)
+
+	fnType := reflect.TypeOf(fn)
+	if fnType.NumIn() == 0 || !targetType.AssignableTo(fnType.In(0)) {
+		panic(fmt.Sprintf("decorator (%v) must take a value of the target type (%v) as its first argument", fnType, targetType))
+	}
+	if !fnType.Out(0).AssignableTo(targetType) {
+		panic(fmt.Sprintf("decorator return type (%v) must be assignable to the target type (%v)", fnType.Out(0), targetType))
+	}
+
+	g.mutex.Lock()
+	defer g.mutex.Unlock()
+	g.decorators = append(g.decorators, decorator{targetType: targetType, fn: fn})
+}
+
+// arguments returns the dependencies of every decorator argument, including the decorated value
+func (d decorator) arguments() []Dependency {
+	fnType := reflect.TypeOf(d.fn)
+	deps := make([]Dependency, fnType.NumIn(), fnType.NumIn())
+	for i := range deps {
+		inType := fnType.In(i)
+		deps[i] = Dependency{Type: inType, Multi: inType.Kind() == reflect.Slice, Lazy: isLazyType(inType)}
+	}
+	return deps
+}
+
+// apply calls the decorator with the value and its auto-resolved arguments
+func (d decorator) apply(g Graph, value reflect.Value) (reflect.Value, error) {
+	lazy := newDeferral(g)
+
+	deps := d.arguments()
+	args := make([]reflect.Value, len(deps), len(deps))
+	arg, err := convertArg(value, deps[0].Type)
+	if err != nil {
+		return reflect.Value{}, err
+	}
+	args[0] = arg
+
+	err = autoResolveArgs(g, lazy, deps, args, 1)
+	if err != nil {
+		return reflect.Value{}, err
+	}
+
+	return callConstructor(d.fn, spreadVariadic(reflect.TypeOf(d.fn), args))
+}
+
+// decoratorsOf returns the decorators that apply to a definition, in the order they are applied
+func decoratorsOf(g Graph, def Definition) []decorator {
+	cg, ok := g.(*graph)
+	if !ok {
+		return nil
+	}
+	var levels [][]decorator
+	for state := cg.graphState; state != nil; state = state.parent {
+		state.mutex.RLock()
+		levels = append(levels, state.decorators)
+		state.mutex.RUnlock()
+	}
+
+	targetType := reflect.TypeOf(def.Ptr()).Elem()
+	var decorators []decorator
+	for i := len(levels) - 1; i >= 0; i-- {
+		for _, d := range levels[i] {
+			if d.targetType == targetType {
+				decorators = append(decorators, d)
+			}
+		}
+	}
+	return decorators
+}
+
+// decorate applies the decorators of a definition to its provided value
+func decorate(g Graph, def Definition, value reflect.Value) (reflect.Value, error) {
+	for _, d := range decoratorsOf(g, def) {
+		var err error
+		value, err = d.apply(g, value)
+		if err != nil {
+			return reflect.Value{}, fmt.Errorf("failed to apply decorator (%v): %w", reflect.TypeOf(d.fn), err)
+		}
+	}
+	return value, nil
+}
+
+// decoratorDependencies returns the auto-resolved dependencies of the decorators of a definition
+func decoratorDependencies(g Graph, def Definition) []Dependency {
+	var deps []Dependency
+	for _, d := range decoratorsOf(g, def) {
+		deps = append(deps, d.arguments()[1:]...)
+	}
+	return deps
+}
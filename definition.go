@@ -3,22 +3,67 @@ package inject
 import (
 	"fmt"
 	"reflect"
+	"sync"
 )
 
 type Definition interface {
 	Ptr() interface{}
-	Resolve(Graph) reflect.Value
-	Obscure(g Graph)
+	Provider() Provider
+	Lifetime() Lifetime
+	Name() string
+	Tags() []string
+	Resolve(Graph) (reflect.Value, error)
+	Obscure(g Graph) error
 	fmt.Stringer
 }
 
 type definition struct {
 	ptr      interface{}
 	provider Provider
-	value    *reflect.Value
+	lifetime Lifetime
+	name     string
+	tags     []string
+	// mutex guards the value, ptr and owner, so that the provider is called at most once per resolution
+	mutex sync.Mutex
+	// released is signalled when the owner finishes resolving
+	released *sync.Cond
+	// owner of the resolution in progress, if any
+	owner *resolver
+	value *reflect.Value
+	// owned is true if the cached value was written to the pointer, which is only done when the resolving graph is
+	// the only one that defines the pointer (see ownsPointer)
+	owned bool
+	// resolution records the cached value with the graph that resolved it, if any
+	resolution *resolution
+	// holders are the transactions that received the cached value before any of them committed
+	holders []*transaction
 }
 
-func NewDefinition(ptr interface{}, provider Provider) Definition {
+// DefinitionOption configures an optional property of a Definition
+type DefinitionOption func(*definition)
+
+// WithLifetime specifies how long the resolved value of a definition is cached. The default is Singleton.
+func WithLifetime(lifetime Lifetime) DefinitionOption {
+	return func(d *definition) {
+		d.lifetime = lifetime
+	}
+}
+
+// Named specifies a name that the definition can be resolved by, without sharing its pointer
+func Named(name string) DefinitionOption {
+	return func(d *definition) {
+		d.name = name
+	}
+}
+
+// Tagged specifies tags that the definition can be found by, without sharing its pointer
+func Tagged(tags ...string) DefinitionOption {
+	return func(d *definition) {
+		d.tags = append(d.tags, tags...)
+	}
+}
+
+func NewDefinition(ptr interface{}, provider Provider, opts ...DefinitionOption) Definition {
 	if reflect.TypeOf(ptr).Kind() != reflect.Ptr {
 		panic("ptr is not a pointer")
 	}
@@ -28,61 +73,253 @@ func NewDefinition(ptr interface{}, provider Provider) Definition {
 		panic(fmt.Sprintf("provider return type (%v) must be assignable to the ptr value type (%v)", provider.ReturnType(), targetType))
 	}
 
-	return &definition{
+	d := &definition{
 		ptr:      ptr,
 		provider: provider,
 	}
+	d.released = sync.NewCond(&d.mutex)
+	for _, opt := range opts {
+		opt(d)
+	}
+	return d
 }
 
-func (d definition) Ptr() interface{} {
+func (d *definition) Ptr() interface{} {
 	return d.ptr
 }
 
-// Resolve calls the provider, initializes the result, and populates the pointer with the result value
-func (d *definition) Resolve(g Graph) reflect.Value {
+func (d *definition) Provider() Provider {
+	return d.provider
+}
+
+func (d *definition) Lifetime() Lifetime {
+	return d.lifetime
+}
+
+func (d *definition) Name() string {
+	return d.name
+}
+
+func (d *definition) Tags() []string {
+	return d.tags
+}
+
+// Resolve calls the provider, initializes the result, and populates the pointer with the result value.
+// Transient definitions call the provider every time, without caching the result.
+// The lock is not held while the provider is called. Instead, other resolutions wait for the owner of the resolution
+// in progress, unless waiting would deadlock, in which case they return an ErrCycle.
+func (d *definition) Resolve(g Graph) (reflect.Value, error) {
+	if d.lifetime == Transient {
+		return d.resolveTransient(g)
+	}
+
+	r := resolverOf(g)
+	owns := ownsPointer(g, d.ptr)
+
+	d.mutex.Lock()
+	for d.owner != nil {
+		if d.owner == r {
+			d.mutex.Unlock()
+			return reflect.Value{}, ErrCycle{Path: pathOf(g)}
+		}
+		if err := r.wait(d.owner, pathOf(g)); err != nil {
+			d.mutex.Unlock()
+			return reflect.Value{}, err
+		}
+		d.released.Wait()
+		r.stopWaiting()
+	}
+
 	if d.value != nil {
 		// already resolved
-		return *d.value
+		value := *d.value
+		d.share(g)
+		d.mutex.Unlock()
+		return value, nil
 	}
 
-	value := d.provider.Provide(g)
+	d.owner = r
+	d.mutex.Unlock()
 
-	obj, ok := value.Interface().(Initializable)
-	if ok && obj != nil {
-		obj.Initialize()
+	value, err := d.provide(g)
+
+	d.mutex.Lock()
+	defer d.mutex.Unlock()
+	d.owner = nil
+	d.released.Broadcast()
+
+	if err != nil {
+		return reflect.Value{}, err
 	}
 
 	// cache the result
 	d.value = &value
 
-	// update the ptr value
-	reflect.ValueOf(d.ptr).Elem().Set(value)
+	// update the ptr value, unless it is shared with other graphs
+	d.owned = owns
+	if owns {
+		reflect.ValueOf(d.ptr).Elem().Set(value)
+	}
+
+	d.resolution = recordResolved(g, d, value)
+	d.holders = nil
+	if tx := txOf(g); tx != nil {
+		d.holders = []*transaction{tx}
+	}
+
+	return value, nil
+}
+
+// share records that the transaction of the graph received the cached value, while the transactions that received it
+// before are still open, so that the value is only rolled back once all of them have rolled back.
+// The caller must hold the lock.
+func (d *definition) share(g Graph) {
+	tx := txOf(g)
+	if tx == nil || d.resolution == nil {
+		return
+	}
+	open := false
+	for _, holder := range d.holders {
+		if holder == tx {
+			return
+		}
+		if holder.ended(txCommitted) {
+			// committed values are never rolled back
+			d.holders = nil
+			return
+		}
+		if !holder.ended(txRolledBack) {
+			open = true
+		}
+	}
+	if open {
+		d.holders = append(d.holders, tx)
+		tx.record(d.resolution)
+	}
+}
+
+// release obscures the definition for a transaction that rolled back, like Obscure, unless the resolution was
+// replaced or its value was also received by another transaction that has not rolled back.
+// Returns true if the value was kept.
+func (d *definition) release(g Graph, tx *transaction, r *resolution) (bool, error) {
+	d.mutex.Lock()
+	defer d.mutex.Unlock()
+
+	if d.value == nil || d.resolution != r {
+		// already obscured, and possibly resolved again by another transaction
+		return false, nil
+	}
+	for _, holder := range d.holders {
+		if holder != tx && !holder.ended(txRolledBack) {
+			return true, nil
+		}
+	}
+	return false, d.obscure(g)
+}
+
+// resolveTransient calls the provider and initializes the result, without holding the lock,
+// so that transient values can be constructed concurrently
+func (d *definition) resolveTransient(g Graph) (reflect.Value, error) {
+	value, err := d.provide(g)
+	if err != nil {
+		return reflect.Value{}, err
+	}
+
+	// update the ptr value, unless it is shared with other graphs
+	if ownsPointer(g, d.ptr) {
+		d.mutex.Lock()
+		reflect.ValueOf(d.ptr).Elem().Set(value)
+		d.mutex.Unlock()
+	}
+
+	recordResolved(g, d, value)
+
+	return value, nil
+}
+
+// provide calls the provider, decorates and initializes the result, converting panics into errors
+func (d *definition) provide(g Graph) (value reflect.Value, err error) {
+	done := observeResolve(g, d)
+	defer func() {
+		done(err)
+	}()
+	defer func() {
+		if r := recover(); r != nil {
+			value, err = reflect.Value{}, ErrPanic{Definition: d, Value: r}
+		}
+	}()
+
+	value, err = d.provider.Provide(g)
+	if err != nil {
+		return reflect.Value{}, err
+	}
+
+	value, err = decorate(g, d, value)
+	if err != nil {
+		return reflect.Value{}, err
+	}
+
+	err = initializeObserved(g, d, value)
+	if err != nil {
+		return reflect.Value{}, fmt.Errorf("failed to initialize %v: %w", value.Type(), err)
+	}
 
-	return value
+	return value, nil
 }
 
-// Obscure zeros out the pointer value and finalizes its previous value
-func (d *definition) Obscure(g Graph) {
+// Obscure zeros out the pointer value and finalizes its previous value.
+// Transient values are finalized by the graph that resolved them.
+func (d *definition) Obscure(g Graph) error {
+	if d.lifetime == Transient {
+		// zero out the ptr value, unless it is shared with other graphs
+		if ownsPointer(g, d.ptr) {
+			d.mutex.Lock()
+			ptrValue := reflect.ValueOf(d.ptr).Elem()
+			ptrValue.Set(reflect.Zero(ptrValue.Type()))
+			d.mutex.Unlock()
+		}
+		return nil
+	}
+
+	d.mutex.Lock()
+	defer d.mutex.Unlock()
+
+	return d.obscure(g)
+}
+
+// obscure uncaches the value, zeros out the pointer value and finalizes the value, if resolved.
+// The caller must hold the lock.
+func (d *definition) obscure(g Graph) error {
 	if d.value == nil {
 		// already obscured
-		return
+		return nil
 	}
 
-	obj, ok := d.value.Interface().(Finalizable)
+	value := *d.value
 
 	// uncache the result
 	d.value = nil
+	d.resolution = nil
+	d.holders = nil
 
-	// zero out the ptr value
-	ptrValue := reflect.ValueOf(d.ptr).Elem()
-	ptrValue.Set(reflect.Zero(ptrValue.Type()))
+	// zero out the ptr value, if it was written
+	if d.owned {
+		ptrValue := reflect.ValueOf(d.ptr).Elem()
+		ptrValue.Set(reflect.Zero(ptrValue.Type()))
+		d.owned = false
+	}
 
-	if ok && obj != nil {
-		obj.Finalize()
+	err := finalizeObserved(g, d, value)
+	if err != nil {
+		return fmt.Errorf("failed to finalize %v: %w", value.Type(), err)
 	}
+	return nil
 }
 
-func (d definition) String() string {
+func (d *definition) String() string {
+	d.mutex.Lock()
+	defer d.mutex.Unlock()
+
 	return fmt.Sprintf("&definition{\n%s,\n%s,\n%s\n}",
 		indent(fmt.Sprintf("ptr: %s", ptrString(d.ptr)), 1),
 		indent(fmt.Sprintf("provider: %s", d.provider), 1),
@@ -0,0 +1,125 @@
+package inject
+
+import (
+	"reflect"
+)
+
+// Description is a structured model of a Graph, with a stable JSON encoding.
+// It does not contain pointer addresses, so it can be compared between runs.
+type Description struct {
+	// Definitions in the order they were defined, with inherited definitions first
+	Definitions []DefinitionDescription `json:"definitions"`
+	// Edges in the order of their dependent definitions and provider dependencies
+	Edges []EdgeDescription `json:"edges"`
+}
+
+// DefinitionDescription describes a Definition and the provider that constructs its value
+type DefinitionDescription struct {
+	// ID is the index of the definition in the Description
+	ID int `json:"id"`
+	// Type is the type of value the definition pointer points to
+	Type string `json:"type"`
+	// Provider is the kind of provider: "provider", "auto", "struct", "value", "func" or the provider's type for
+	// custom providers
+	Provider string `json:"provider"`
+	// Signature is the type of the provider constructor, or the provider's return type if it has no constructor
+	Signature string   `json:"signature"`
+	Lifetime  string   `json:"lifetime"`
+	Name      string   `json:"name,omitempty"`
+	Tags      []string `json:"tags,omitempty"`
+	// Module is the name of the module that installed the definition, if any
+	Module string `json:"module,omitempty"`
+	// Private is true if the definition is hidden outside of its module
+	Private bool `json:"private,omitempty"`
+	// Resolved is true if the value of the definition is cached by the graph
+	Resolved bool `json:"resolved"`
+}
+
+// EdgeDescription describes a dependency of one definition (From) on another (To), by ID
+type EdgeDescription struct {
+	From int `json:"from"`
+	To   int `json:"to"`
+	// Lazy is true if the dependency is resolved after the dependent is constructed
+	Lazy bool `json:"lazy,omitempty"`
+}
+
+// Describe returns a structured model of the definitions of the graph, including those inherited from parents,
+// and the dependency edges between them
+func (g *graph) Describe() Description {
+	defs, edges := g.dependencyGraph()
+
+	d := Description{
+		Definitions: make([]DefinitionDescription, len(defs), len(defs)),
+		Edges:       make([]EdgeDescription, len(edges), len(edges)),
+	}
+	for i, def := range defs {
+		d.Definitions[i] = DefinitionDescription{
+			ID:        i,
+			Type:      reflect.TypeOf(def.Ptr()).Elem().String(),
+			Provider:  providerKind(def.Provider()),
+			Signature: providerSignature(def.Provider()),
+			Lifetime:  def.Lifetime().String(),
+			Name:      def.Name(),
+			Tags:      append([]string(nil), def.Tags()...),
+			Resolved:  g.isResolved(def),
+		}
+		if mod := g.moduleOf(def); mod != nil {
+			d.Definitions[i].Module = mod.Name()
+			d.Definitions[i].Private = !mod.IsExported(def)
+		}
+	}
+	for i, e := range edges {
+		d.Edges[i] = EdgeDescription{From: e.from, To: e.to, Lazy: e.lazy}
+	}
+	return d
+}
+
+// edge is a dependency of one definition on another, by index in the list of definitions
+type edge struct {
+	from int
+	to   int
+	// lazy edges are resolved after the dependent is constructed
+	lazy bool
+}
+
+// dependencyGraph returns the definitions of the graph, including those inherited from parents,
+// and the dependency edges between them
+func (g *graph) dependencyGraph() ([]Definition, []edge) {
+	defs := g.snapshot()
+	index := make(map[Definition]int, len(defs))
+	for i, def := range defs {
+		index[def] = i
+	}
+
+	var edges []edge
+	for i, def := range defs {
+		mg := g.within(g.moduleOf(def))
+		for _, dep := range mg.definitionDependencies(def) {
+			lazy := resolvesLazily(mg, dep)
+			if lazy {
+				dep = lazyDependency(dep)
+			}
+			for _, depDef := range mg.dependencyDefinitions(dep) {
+				if j, found := index[depDef]; found {
+					edges = append(edges, edge{from: i, to: j, lazy: lazy})
+				}
+			}
+		}
+	}
+	return defs, edges
+}
+
+// isResolved returns true if the graph has a cached value for the definition.
+// Transient definitions are never cached.
+func (g *graph) isResolved(def Definition) bool {
+	for state := g.graphState; state != nil; state = state.parent {
+		state.mutex.RLock()
+		scoped, found := state.scoped[def]
+		resolved := state.resolvedSet[def] || (found && state.resolvedSet[scoped])
+		state.mutex.RUnlock()
+		if resolved || found {
+			return resolved
+		}
+	}
+	return false
+}
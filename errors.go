@@ -0,0 +1,164 @@
+package inject
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+)
+
+// ErrNotPointer describes a value that was required to be a pointer (or a pointer to a slice), but was not
+type ErrNotPointer struct {
+	Type  reflect.Type
+	Slice bool
+}
+
+func (e ErrNotPointer) Error() string {
+	if e.Slice {
+		return fmt.Sprintf("(%v) is not a pointer to a slice or array", e.Type)
+	}
+	return fmt.Sprintf("(%v) is not a pointer", e.Type)
+}
+
+// ErrNoMatch describes a type (or name) lookup that found no defined pointers
+type ErrNoMatch struct {
+	Type       reflect.Type
+	Assignable bool
+	Name       string
+}
+
+func (e ErrNoMatch) Error() string {
+	if e.Name != "" {
+		return fmt.Sprintf("no defined pointer is named %q", e.Name)
+	}
+	if e.Assignable {
+		return fmt.Sprintf("no defined pointer is assignable to the specified type (%v)", e.Type)
+	}
+	return fmt.Sprintf("no defined pointer matches the specified type (%v)", e.Type)
+}
+
+// ErrAmbiguous describes a type (or name) lookup that found more than one defined pointer,
+// when exactly one was required
+type ErrAmbiguous struct {
+	Type       reflect.Type
+	Assignable bool
+	Name       string
+	Candidates []Definition
+}
+
+func (e ErrAmbiguous) Error() string {
+	ptrs := make([]string, len(e.Candidates), len(e.Candidates))
+	for i, def := range e.Candidates {
+		ptrs[i] = ptrString(def.Ptr())
+	}
+	if e.Name != "" {
+		return fmt.Sprintf("more than one defined pointer is named %q: %s", e.Name, arrayString(ptrs))
+	}
+	if e.Assignable {
+		return fmt.Sprintf("more than one defined pointer is assignable to the specified type (%v): %s", e.Type, arrayString(ptrs))
+	}
+	return fmt.Sprintf("more than one defined pointer matches the specified type (%v): %s", e.Type, arrayString(ptrs))
+}
+
+// ErrCycle describes a definition that depends on itself, directly or transitively.
+// The path starts and ends with the same definition.
+type ErrCycle struct {
+	Path []Definition
+}
+
+func (e ErrCycle) Error() string {
+	nodes := make([]string, len(e.Path), len(e.Path))
+	for i, def := range e.Path {
+		nodes[i] = definitionLabel(def)
+	}
+	return fmt.Sprintf("dependency cycle detected: %s", strings.Join(nodes, " -> "))
+}
+
+// ErrTypeMismatch describes an argument value that cannot be assigned or converted to the constructor argument type
+type ErrTypeMismatch struct {
+	From reflect.Type
+	To   reflect.Type
+}
+
+func (e ErrTypeMismatch) Error() string {
+	return fmt.Sprintf("type (%v) cannot be assigned or converted to type (%v)", e.From, e.To)
+}
+
+// ErrDependency describes a provider argument (or struct field) of a definition that cannot be resolved
+type ErrDependency struct {
+	Definition Definition
+	Index      int
+	Field      string
+	Err        error
+}
+
+func (e ErrDependency) Error() string {
+	if e.Field != "" {
+		return fmt.Sprintf("%s field %s: %v", definitionLabel(e.Definition), e.Field, e.Err)
+	}
+	return fmt.Sprintf("%s argument %d: %v", definitionLabel(e.Definition), e.Index, e.Err)
+}
+
+func (e ErrDependency) Unwrap() error {
+	return e.Err
+}
+
+// ErrValidation describes every problem found while validating a graph
+type ErrValidation struct {
+	Errors []error
+}
+
+func (e ErrValidation) Error() string {
+	msgs := make([]string, len(e.Errors), len(e.Errors))
+	for i, err := range e.Errors {
+		msgs[i] = err.Error()
+	}
+	return fmt.Sprintf("graph validation failed: %s", arrayString(msgs))
+}
+
+func (e ErrValidation) Unwrap() []error {
+	return e.Errors
+}
+
+// ErrPanic describes a provider constructor or initializer that panicked while resolving a definition
+type ErrPanic struct {
+	Definition Definition
+	Value      interface{}
+}
+
+func (e ErrPanic) Error() string {
+	return fmt.Sprintf("%s panicked: %v", definitionLabel(e.Definition), e.Value)
+}
+
+// Unwrap returns the panic value, if it is an error (ex: a panic from Resolve or a Lazy argument)
+func (e ErrPanic) Unwrap() error {
+	if err, ok := e.Value.(error); ok {
+		return err
+	}
+	return nil
+}
+
+// ErrDuplicate describes a definition added to a graph that already has a definition with the same pointer
+type ErrDuplicate struct {
+	Definition Definition
+	Existing   Definition
+}
+
+func (e ErrDuplicate) Error() string {
+	return fmt.Sprintf("pointer (%s) is already defined by %s, use Override to replace it", ptrString(e.Definition.Ptr()), definitionLabel(e.Existing))
+}
+
+// ErrOverrideResolved describes a definition that cannot be overridden, because it has already been resolved
+type ErrOverrideResolved struct {
+	Definition Definition
+}
+
+func (e ErrOverrideResolved) Error() string {
+	return fmt.Sprintf("%s cannot be overridden, because it has already been resolved", definitionLabel(e.Definition))
+}
+
+// must panics with the error if it is not nil, preserving the panic-style API
+func must(err error) {
+	if err != nil {
+		panic(err)
+	}
+}
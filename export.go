@@ -0,0 +1,89 @@
+package inject
+
+import (
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+)
+
+// nodeLabel returns the lines of a node label: the pointer type, the provider signature, and the name, if any
+func nodeLabel(def DefinitionDescription) []string {
+	lines := []string{"*" + def.Type, def.Signature}
+	if def.Name != "" {
+		lines = append(lines, fmt.Sprintf("name: %s", def.Name))
+	}
+	return lines
+}
+
+// WriteDOT writes the dependency graph in Graphviz DOT format, with one node per definition and one edge per
+// dependency. Resolved definitions are filled and lazy dependencies are dashed.
+func WriteDOT(w io.Writer, g Graph) error {
+	d := g.Describe()
+
+	var b strings.Builder
+	b.WriteString("digraph inject {\n")
+	b.WriteString("  node [shape=box];\n")
+	for _, def := range d.Definitions {
+		attrs := fmt.Sprintf("label=%s", strconv.Quote(strings.Join(nodeLabel(def), "\n")))
+		if def.Resolved {
+			attrs += ", style=filled, fillcolor=palegreen"
+		}
+		fmt.Fprintf(&b, "  n%d [%s];\n", def.ID, attrs)
+	}
+	for _, e := range d.Edges {
+		if e.Lazy {
+			fmt.Fprintf(&b, "  n%d -> n%d [style=dashed];\n", e.From, e.To)
+		} else {
+			fmt.Fprintf(&b, "  n%d -> n%d;\n", e.From, e.To)
+		}
+	}
+	b.WriteString("}\n")
+
+	_, err := io.WriteString(w, b.String())
+	return err
+}
+
+// WriteMermaid writes the dependency graph as a Mermaid flowchart, with one node per definition and one edge per
+// dependency. Resolved definitions have the "resolved" class and lazy dependencies are dotted.
+func WriteMermaid(w io.Writer, g Graph) error {
+	d := g.Describe()
+
+	var b strings.Builder
+	b.WriteString("flowchart TD\n")
+	var resolved []string
+	for _, def := range d.Definitions {
+		lines := nodeLabel(def)
+		for j, line := range lines {
+			lines[j] = mermaidEscape(line)
+		}
+		fmt.Fprintf(&b, "  n%d[\"%s\"]\n", def.ID, strings.Join(lines, "<br/>"))
+		if def.Resolved {
+			resolved = append(resolved, fmt.Sprintf("n%d", def.ID))
+		}
+	}
+	for _, e := range d.Edges {
+		if e.Lazy {
+			fmt.Fprintf(&b, "  n%d -.-> n%d\n", e.From, e.To)
+		} else {
+			fmt.Fprintf(&b, "  n%d --> n%d\n", e.From, e.To)
+		}
+	}
+	b.WriteString("  classDef resolved fill:#98fb98\n")
+	if len(resolved) > 0 {
+		fmt.Fprintf(&b, "  class %s resolved\n", strings.Join(resolved, ","))
+	}
+
+	_, err := io.WriteString(w, b.String())
+	return err
+}
+
+// mermaidEscape escapes the characters that cannot appear in a quoted Mermaid label
+func mermaidEscape(text string) string {
+	return strings.NewReplacer(
+		"&", "#amp;",
+		"\"", "#quot;",
+		"<", "#lt;",
+		">", "#gt;",
+	).Replace(text)
+}
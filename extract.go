@@ -1,52 +1,65 @@
 package inject
 
 import (
-	"fmt"
 	"reflect"
 )
 
 // ExtractByType resolves a pointer into a value by finding exactly one defined pointer with the specified type
 func ExtractByType(g Graph, ptr interface{}) reflect.Value {
+	value, err := TryExtractByType(g, ptr)
+	must(err)
+	return value
+}
+
+// TryExtractByType resolves a pointer into a value, like ExtractByType, but returns an error instead of panicking
+func TryExtractByType(g Graph, ptr interface{}) (reflect.Value, error) {
+	return extract(g, ptr, false)
+}
+
+// ExtractAssignable resolves a pointer into a value by finding exactly one defined pointer with an assignable type
+func ExtractAssignable(g Graph, ptr interface{}) reflect.Value {
+	value, err := TryExtractAssignable(g, ptr)
+	must(err)
+	return value
+}
+
+// TryExtractAssignable resolves a pointer into a value, like ExtractAssignable, but returns an error instead of panicking
+func TryExtractAssignable(g Graph, ptr interface{}) (reflect.Value, error) {
+	return extract(g, ptr, true)
+}
+
+func extract(g Graph, ptr interface{}, assignable bool) (reflect.Value, error) {
 	ptrType := reflect.TypeOf(ptr)
-	if ptrType.Kind() != reflect.Ptr {
-		panic(fmt.Sprintf("ptr (%v) is not a pointer", ptrType))
+	if ptrType == nil || ptrType.Kind() != reflect.Ptr {
+		return reflect.Value{}, ErrNotPointer{Type: ptrType}
 	}
 
-	targetType := reflect.ValueOf(ptr).Elem().Type()
-	values := g.ResolveByType(targetType)
-
-	if len(values) > 1 {
-		panic(fmt.Sprintf("more than one defined pointer matches the specified type (%v)", ptr))
-	} else if len(values) == 0 {
-		panic(fmt.Sprintf("no defined pointer matches the specified type (%v)", ptr))
+	targetType := ptrType.Elem()
+	value, err := resolveOne(g, targetType, assignable)
+	if err != nil {
+		return reflect.Value{}, err
 	}
-	value := values[0]
 
 	// update the ptr value
 	reflect.ValueOf(ptr).Elem().Set(value)
 
-	return value
+	return value, nil
 }
 
-// ExtractAssignable resolves a pointer into a value by finding exactly one defined pointer with an assignable type
-func ExtractAssignable(g Graph, ptr interface{}) reflect.Value {
-	ptrType := reflect.TypeOf(ptr)
-	if ptrType.Kind() != reflect.Ptr {
-		panic(fmt.Sprintf("ptr (%v) is not a pointer", ptrType))
+// resolveOne finds exactly one defined pointer with the specified (or assignable) type and resolves it
+func resolveOne(g Graph, targetType reflect.Type, assignable bool) (reflect.Value, error) {
+	var defs []Definition
+	if assignable {
+		defs = g.DefinitionsByAssignableType(targetType)
+	} else {
+		defs = g.DefinitionsByType(targetType)
 	}
 
-	targetType := reflect.ValueOf(ptr).Elem().Type()
-	values := g.ResolveByAssignableType(targetType)
-
-	if len(values) > 1 {
-		panic(fmt.Sprintf("more than one defined pointer is assignable to the specified type (%v)", ptr))
-	} else if len(values) == 0 {
-		panic(fmt.Sprintf("no defined pointer is assignable to the specified type (%v)", ptr))
+	if len(defs) > 1 {
+		return reflect.Value{}, ErrAmbiguous{Type: targetType, Assignable: assignable, Candidates: defs}
+	} else if len(defs) == 0 {
+		return reflect.Value{}, ErrNoMatch{Type: targetType, Assignable: assignable}
 	}
-	value := values[0]
 
-	// update the ptr value
-	reflect.ValueOf(ptr).Elem().Set(value)
-
-	return value
+	return g.TryResolve(defs[0].Ptr())
 }
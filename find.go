@@ -1,54 +1,60 @@
 package inject
 
 import (
-	"fmt"
 	"reflect"
 )
 
 // FindByType resolves all defined pointers that match the type of the supplied slice
 // and appends the resolved values to the slice.
 func FindByType(g Graph, listPtr interface{}) []reflect.Value {
-	ptrType := reflect.TypeOf(listPtr)
-	if ptrType.Kind() != reflect.Ptr {
-		panic(fmt.Sprintf("listPtr (%v) is not a pointer", ptrType))
-	}
-
-	listType := ptrType.Elem()
-	if listType.Kind() != reflect.Slice {
-		panic(fmt.Sprintf("listPtr (%v) is not a pointer to a slice or array", ptrType))
-	}
-
-	listValue := reflect.ValueOf(listPtr).Elem()
-
-	values := g.ResolveByType(listType.Elem())
-	listValue = reflect.Append(listValue, values...)
-
-	// update the listPtr value
-	reflect.ValueOf(listPtr).Elem().Set(listValue)
-
+	values, err := TryFindByType(g, listPtr)
+	must(err)
 	return values
 }
 
+// TryFindByType resolves all defined pointers that match the type of the supplied slice, like FindByType,
+// but returns an error instead of panicking.
+func TryFindByType(g Graph, listPtr interface{}) ([]reflect.Value, error) {
+	return find(g, listPtr, false)
+}
+
 // FindAssignable resolves all defined pointers that are assignable to the type of the supplied slice
 // and appends the resolved values to the slice.
 func FindAssignable(g Graph, listPtr interface{}) []reflect.Value {
+	values, err := TryFindAssignable(g, listPtr)
+	must(err)
+	return values
+}
+
+// TryFindAssignable resolves all defined pointers that are assignable to the type of the supplied slice,
+// like FindAssignable, but returns an error instead of panicking.
+func TryFindAssignable(g Graph, listPtr interface{}) ([]reflect.Value, error) {
+	return find(g, listPtr, true)
+}
+
+func find(g Graph, listPtr interface{}, assignable bool) ([]reflect.Value, error) {
 	ptrType := reflect.TypeOf(listPtr)
-	if ptrType.Kind() != reflect.Ptr {
-		panic(fmt.Sprintf("listPtr (%v) is not a pointer", ptrType))
+	if ptrType == nil || ptrType.Kind() != reflect.Ptr || ptrType.Elem().Kind() != reflect.Slice {
+		return nil, ErrNotPointer{Type: ptrType, Slice: true}
 	}
 
 	listType := ptrType.Elem()
-	if listType.Kind() != reflect.Slice {
-		panic(fmt.Sprintf("listPtr (%v) is not a pointer to a slice or array", ptrType))
-	}
-
 	listValue := reflect.ValueOf(listPtr).Elem()
 
-	values := g.ResolveByAssignableType(listType.Elem())
+	var values []reflect.Value
+	var err error
+	if assignable {
+		values, err = g.TryResolveByAssignableType(listType.Elem())
+	} else {
+		values, err = g.TryResolveByType(listType.Elem())
+	}
+	if err != nil {
+		return nil, err
+	}
 	listValue = reflect.Append(listValue, values...)
 
 	// update the listPtr value
 	reflect.ValueOf(listPtr).Elem().Set(listValue)
 
-	return values
+	return values, nil
 }
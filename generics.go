@@ -0,0 +1,98 @@
+package inject
+
+import (
+	"fmt"
+	"reflect"
+)
+
+// Provide defines a new pointer of type T, resolved by calling the constructor with auto-resolved arguments,
+// and returns the pointer. The constructor must return a value assignable to T (and optionally an error).
+// The constructor is not compile-checked: it may be any function, and panics at run time if it does not return T.
+// Use Provide0 to Provide3 (or ProvideErr0 to ProvideErr3) to have the compiler check the constructor.
+func Provide[T any](g Graph, constructor interface{}, opts ...DefinitionOption) *T {
+	ptr := new(T)
+	g.Define(ptr, NewAutoProvider(constructor), opts...)
+	return ptr
+}
+
+// Provide0 defines a new pointer of type T, like Provide, with a compile-checked constructor that takes no arguments
+func Provide0[T any](g Graph, constructor func() T, opts ...DefinitionOption) *T {
+	return Provide[T](g, constructor, opts...)
+}
+
+// Provide1 defines a new pointer of type T, like Provide, with a compile-checked constructor that takes one argument
+func Provide1[T, A any](g Graph, constructor func(A) T, opts ...DefinitionOption) *T {
+	return Provide[T](g, constructor, opts...)
+}
+
+// Provide2 defines a new pointer of type T, like Provide, with a compile-checked constructor that takes two arguments
+func Provide2[T, A, B any](g Graph, constructor func(A, B) T, opts ...DefinitionOption) *T {
+	return Provide[T](g, constructor, opts...)
+}
+
+// Provide3 defines a new pointer of type T, like Provide, with a compile-checked constructor that takes three arguments
+func Provide3[T, A, B, C any](g Graph, constructor func(A, B, C) T, opts ...DefinitionOption) *T {
+	return Provide[T](g, constructor, opts...)
+}
+
+// ProvideErr0 defines a new pointer of type T, like Provide0, with a constructor that may fail
+func ProvideErr0[T any](g Graph, constructor func() (T, error), opts ...DefinitionOption) *T {
+	return Provide[T](g, constructor, opts...)
+}
+
+// ProvideErr1 defines a new pointer of type T, like Provide1, with a constructor that may fail
+func ProvideErr1[T, A any](g Graph, constructor func(A) (T, error), opts ...DefinitionOption) *T {
+	return Provide[T](g, constructor, opts...)
+}
+
+// ProvideErr2 defines a new pointer of type T, like Provide2, with a constructor that may fail
+func ProvideErr2[T, A, B any](g Graph, constructor func(A, B) (T, error), opts ...DefinitionOption) *T {
+	return Provide[T](g, constructor, opts...)
+}
+
+// ProvideErr3 defines a new pointer of type T, like Provide3, with a constructor that may fail
+func ProvideErr3[T, A, B, C any](g Graph, constructor func(A, B, C) (T, error), opts ...DefinitionOption) *T {
+	return Provide[T](g, constructor, opts...)
+}
+
+// Get resolves exactly one defined pointer assignable to type T
+func Get[T any](g Graph) (T, error) {
+	var value T
+	_, err := TryExtractAssignable(g, &value)
+	return value, err
+}
+
+// MustGet resolves exactly one defined pointer assignable to type T, like Get, but panics instead of returning an error
+func MustGet[T any](g Graph) T {
+	value, err := Get[T](g)
+	must(err)
+	return value
+}
+
+// GetNamed resolves the definition with the specified name, which must be assignable to type T
+func GetNamed[T any](g Graph, name string) (T, error) {
+	var value T
+	resolved, err := g.TryResolveNamed(name)
+	if err != nil {
+		return value, err
+	}
+	value, ok := resolved.Interface().(T)
+	if !ok && resolved.Interface() != nil {
+		return value, fmt.Errorf("definition named %q: %w", name, ErrTypeMismatch{From: resolved.Type(), To: reflect.TypeOf(&value).Elem()})
+	}
+	return value, nil
+}
+
+// All resolves all defined pointers assignable to type T
+func All[T any](g Graph) []T {
+	values, err := TryAll[T](g)
+	must(err)
+	return values
+}
+
+// TryAll resolves all defined pointers assignable to type T, like All, but returns an error instead of panicking
+func TryAll[T any](g Graph) ([]T, error) {
+	var values []T
+	_, err := TryFindAssignable(g, &values)
+	return values, err
+}
@@ -1,115 +1,463 @@
 package inject
 
 import (
+	"context"
+	"errors"
 	"fmt"
 	"reflect"
 	"sort"
+	"sync"
+	"sync/atomic"
+	"time"
 )
 
 // Graph describes a dependency graph that resolves nodes using well defined relationships.
 // These relationships are defined with node pointers and Providers.
+// Graphs are safe for concurrent use. Each singleton definition is resolved at most once (and each Scoped definition
+// once per scope), even when resolved concurrently. Transient definitions are resolved every time.
+// Lookups that return multiple definitions (or values) return them in the order they were defined,
+// with inherited definitions first.
 type Graph interface {
 	Finalizable
 	Add(Definition)
-	Define(ptr interface{}, provider Provider) Definition
+	TryAdd(Definition) error
+	Override(Definition)
+	TryOverride(Definition) error
+	Define(ptr interface{}, provider Provider, opts ...DefinitionOption) Definition
 	Resolve(ptr interface{}) reflect.Value
+	TryResolve(ptr interface{}) (reflect.Value, error)
+	ResolveContext(ctx context.Context, ptr interface{}) (reflect.Value, error)
 	ResolveByType(ptrType reflect.Type) []reflect.Value
+	TryResolveByType(ptrType reflect.Type) ([]reflect.Value, error)
 	ResolveByAssignableType(ptrType reflect.Type) []reflect.Value
+	TryResolveByAssignableType(ptrType reflect.Type) ([]reflect.Value, error)
 	ResolveAll() []reflect.Value
+	TryResolveAll() ([]reflect.Value, error)
+	ResolveAllParallel(workers int) []reflect.Value
+	TryResolveAllParallel(workers int) ([]reflect.Value, error)
+	DefinitionsByType(ptrType reflect.Type) []Definition
+	DefinitionsByAssignableType(ptrType reflect.Type) []Definition
+	ResolveNamed(name string) reflect.Value
+	TryResolveNamed(name string) (reflect.Value, error)
+	ResolveByTag(tag string) []reflect.Value
+	TryResolveByTag(tag string) ([]reflect.Value, error)
+	DefinitionsByName(name string) []Definition
+	DefinitionsByTag(tag string) []Definition
+	Validate() error
+	Describe() Description
+	Observe(Observer)
+	Decorate(targetType reflect.Type, decorator interface{})
+	Install(Module)
+	TryInstall(Module) error
+	FinalizeContext(ctx context.Context) error
+	NewScope() Graph
 	fmt.Stringer
 }
 
 type graph struct {
+	*graphState
+	// path of definitions currently being resolved, used to detect dependency cycles
+	path []Definition
+	// ctx of the current resolution or finalization, if any
+	ctx context.Context
+	// tx of the current top-level resolution, if any
+	tx *transaction
+	// module whose private definitions are visible to the current resolution, if any
+	module Module
+	// resolver of the current chain of nested resolutions, if any
+	resolver *resolver
+	// frame of the definition currently being resolved, if any
+	frame *frame
+}
+
+// graphState is shared by a graph and the copies it makes while resolving
+type graphState struct {
+	// parent of a scope, or nil
+	parent *graphState
+	// mutex guards the definitions and the resolution order
+	mutex       sync.RWMutex
 	definitions map[interface{}]Definition
+	// pointers of the definitions, in the order they were first added
+	order []interface{}
+	// scoped instances of Scoped definitions inherited from the parent, keyed by the parent definition
+	scoped map[Definition]Definition
+	// scoped instances, in the order they were created
+	scopedOrder []Definition
+	// resolved definitions, in the order they finished resolving, so they can be finalized in reverse
+	resolved    []*resolution
+	resolvedSet map[Definition]bool
+	// observers notified of events in the graph and its scopes
+	observers []Observer
+	// decorators applied to values resolved by the graph and its scopes
+	decorators []decorator
+	// modules installed in the graph, and the module that installed each definition
+	installed map[Module]bool
+	modules   map[Definition]Module
 }
 
 // NewGraph constructs a new Graph, initializing the provider and value maps.
+// Panics if more than one definition has the same pointer.
 func NewGraph(defs ...Definition) Graph {
-	defMap := make(map[interface{}]Definition, len(defs))
+	state := newGraphState(nil)
 	for _, def := range defs {
-		defMap[def.Ptr()] = def
+		must(state.add(def, false))
 	}
 	return &graph{
-		definitions: defMap,
+		graphState: state,
+	}
+}
+
+func newGraphState(parent *graphState) *graphState {
+	return &graphState{
+		parent:      parent,
+		definitions: make(map[interface{}]Definition),
+		scoped:      make(map[Definition]Definition),
+		resolvedSet: make(map[Definition]bool),
+		installed:   make(map[Module]bool),
+		modules:     make(map[Definition]Module),
 	}
 }
 
+// Add a definition. Panics if the graph already has a definition with the same pointer (see Override).
+// Definitions added to a scope may shadow inherited definitions with the same pointer.
 func (g *graph) Add(def Definition) {
+	must(g.TryAdd(def))
+}
+
+// TryAdd adds a definition, like Add, but returns an ErrDuplicate instead of panicking
+func (g *graph) TryAdd(def Definition) error {
+	g.mutex.Lock()
+	defer g.mutex.Unlock()
+	return g.add(def, false)
+}
+
+// Override adds a definition that intentionally replaces any definition with the same pointer, keeping its original
+// position (ex: replacing a concrete implementation with a mock).
+// Panics if the replaced definition has already been resolved.
+func (g *graph) Override(def Definition) {
+	must(g.TryOverride(def))
+}
+
+// TryOverride replaces a definition, like Override, but returns an ErrOverrideResolved instead of panicking
+func (g *graph) TryOverride(def Definition) error {
+	g.mutex.Lock()
+	defer g.mutex.Unlock()
+	return g.add(def, true)
+}
+
+// add a definition, replacing any definition with the same pointer in its original position, if overriding.
+// A replacement belongs to the module of the definition it replaces, if any.
+// The caller must hold the lock.
+func (g *graphState) add(def Definition, override bool) error {
+	existing, found := g.definitions[def.Ptr()]
+	if found {
+		if !override {
+			return ErrDuplicate{Definition: def, Existing: existing}
+		}
+		if g.resolvedSet[existing] {
+			return ErrOverrideResolved{Definition: existing}
+		}
+		if mod, found := g.modules[existing]; found {
+			delete(g.modules, existing)
+			g.modules[def] = mod
+		}
+	} else {
+		g.order = append(g.order, def.Ptr())
+	}
 	g.definitions[def.Ptr()] = def
+	return nil
+}
+
+// ordered returns the definitions in the order they were added. The caller must hold the lock.
+func (g *graphState) ordered() []Definition {
+	defs := make([]Definition, len(g.order), len(g.order))
+	for i, ptr := range g.order {
+		defs[i] = g.definitions[ptr]
+	}
+	return defs
 }
 
 // Define a pointer as being resolved by a provider
-func (g *graph) Define(ptr interface{}, provider Provider) Definition {
-	def := NewDefinition(ptr, provider)
+func (g *graph) Define(ptr interface{}, provider Provider, opts ...DefinitionOption) Definition {
+	def := NewDefinition(ptr, provider, opts...)
 	g.Add(def)
 	return def
 }
 
 // Resolve a pointer into a value by recursively resolving its dependencies and/or returning the cached result
 func (g *graph) Resolve(ptr interface{}) reflect.Value {
+	value, err := g.TryResolve(ptr)
+	must(err)
+	return value
+}
+
+// TryResolve a pointer into a value, like Resolve, but returns an error instead of panicking
+func (g *graph) TryResolve(ptr interface{}) (reflect.Value, error) {
+	var value reflect.Value
+	err := g.transact(func(tg *graph) error {
+		var err error
+		value, err = tg.resolve(ptr)
+		return err
+	})
+	return value, err
+}
+
+func (g *graph) resolve(ptr interface{}) (reflect.Value, error) {
 	ptrType := reflect.TypeOf(ptr)
-	if ptrType.Kind() != reflect.Ptr {
-		panic(fmt.Sprintf("ptr (%v) is not a pointer", ptrType))
+	if ptrType == nil || ptrType.Kind() != reflect.Ptr {
+		return reflect.Value{}, ErrNotPointer{Type: ptrType}
 	}
 
 	ptrValueElem := reflect.ValueOf(ptr).Elem()
-	def, found := g.definitions[ptr]
+	def, owner, found := g.lookup(ptr)
 	if !found {
 		// no known definition - return the current value of the pointer
-		return ptrValueElem
+		return ptrValueElem, nil
+	}
+
+	if err := g.context().Err(); err != nil {
+		return reflect.Value{}, err
+	}
+
+	// definitions inherited from a parent are resolved by the parent, unless they are transient or scoped
+	rg := g.within(g.moduleOf(def))
+	if owner != g.graphState {
+		switch def.Lifetime() {
+		case Transient:
+		case Scoped:
+			def = g.scopedDefinition(def)
+		default:
+			rg = rg.in(owner)
+		}
+	}
+
+	if i := indexOf(g.path, def); i >= 0 {
+		return reflect.Value{}, ErrCycle{Path: append(append([]Definition{}, g.path[i:]...), def)}
+	}
+
+	if rg.resolver == nil {
+		rg.resolver = &resolver{}
+	}
+
+	start := time.Now()
+	rg = rg.resolving(def)
+	value, err := def.Resolve(rg)
+	rg.frame.finish()
+	if g.frame != nil {
+		// the caller's own time excludes the time spent resolving its dependencies
+		g.frame.nest(time.Since(start))
+	}
+	return value, err
+}
+
+// ResolveContext resolves a pointer into a value, like TryResolve, but stops resolving dependencies once the context
+// is done and passes the context to any InitializableContext values
+func (g *graph) ResolveContext(ctx context.Context, ptr interface{}) (reflect.Value, error) {
+	return g.withContext(ctx).TryResolve(ptr)
+}
+
+// definition returns the definition of a pointer, if it is defined in the graph or its parents
+func (g *graph) definition(ptr interface{}) (Definition, bool) {
+	def, _, found := g.lookup(ptr)
+	return def, found
+}
+
+// snapshot returns a copy of the list of definitions, including those inherited from parents and private module
+// definitions, so that they can be iterated without holding the lock
+func (g *graph) snapshot() []Definition {
+	return g.collect(func(def Definition) bool { return true }, true)
+}
+
+// withContext returns a copy of the graph that resolves and finalizes with the context
+func (g *graph) withContext(ctx context.Context) *graph {
+	next := *g
+	next.ctx = ctx
+	return &next
+}
+
+// context returns the context of the current resolution or finalization, or the background context
+func (g *graph) context() context.Context {
+	if g.ctx == nil {
+		return context.Background()
+	}
+	return g.ctx
+}
+
+// contextOf returns the context of the graph's current resolution or finalization, or the background context
+func contextOf(g Graph) context.Context {
+	if cg, ok := g.(*graph); ok {
+		return cg.context()
+	}
+	return context.Background()
+}
+
+// detached returns a copy of the graph without the resolution path, context and transaction of the current resolution
+func detached(g Graph) Graph {
+	if cg, ok := g.(*graph); ok {
+		return &graph{graphState: cg.graphState, module: cg.module}
 	}
+	return g
+}
+
+// resolving returns a copy of the graph that records the definition as being resolved,
+// so that dependencies resolved through the copy can detect cycles
+func (g *graph) resolving(def Definition) *graph {
+	next := *g
+	next.path = append(append(make([]Definition, 0, len(g.path)+1), g.path...), def)
+	next.frame = &frame{}
+	return &next
+}
+
+// frame records whether the resolution of a definition has finished,
+// and how long it spent resolving nested definitions
+type frame struct {
+	done   atomic.Bool
+	nested atomic.Int64
+}
+
+func (f *frame) finish() {
+	f.done.Store(true)
+}
+
+func (f *frame) finished() bool {
+	return f.done.Load()
+}
+
+func (f *frame) nest(duration time.Duration) {
+	f.nested.Add(int64(duration))
+}
 
-	return def.Resolve(g)
+// exclusive returns the part of a duration that was not spent resolving nested definitions
+func (f *frame) exclusive(duration time.Duration) time.Duration {
+	return duration - time.Duration(f.nested.Load())
 }
 
 // Resolve a type into a list of values by resolving all defined pointers with that exact type
 func (g *graph) ResolveByType(ptrType reflect.Type) []reflect.Value {
-	var values []reflect.Value
-	for ptr, def := range g.definitions {
-		if reflect.TypeOf(ptr).Elem() == ptrType {
-			values = append(values, def.Resolve(g))
-		}
-	}
+	values, err := g.TryResolveByType(ptrType)
+	must(err)
 	return values
 }
 
+// TryResolveByType resolves a type into a list of values, like ResolveByType, but returns an error instead of panicking
+func (g *graph) TryResolveByType(ptrType reflect.Type) ([]reflect.Value, error) {
+	var values []reflect.Value
+	err := g.transact(func(tg *graph) error {
+		var err error
+		values, err = tg.resolveDefinitions(tg.DefinitionsByType(ptrType))
+		return err
+	})
+	return values, err
+}
+
 // Resolve a type into a list of values by resolving all defined pointers assignable to that type
 func (g *graph) ResolveByAssignableType(ptrType reflect.Type) []reflect.Value {
-	var values []reflect.Value
-	for ptr, def := range g.definitions {
-		if reflect.TypeOf(ptr).Elem().AssignableTo(ptrType) {
-			values = append(values, def.Resolve(g))
-		}
-	}
+	values, err := g.TryResolveByAssignableType(ptrType)
+	must(err)
 	return values
 }
 
+// TryResolveByAssignableType resolves a type into a list of values, like ResolveByAssignableType,
+// but returns an error instead of panicking
+func (g *graph) TryResolveByAssignableType(ptrType reflect.Type) ([]reflect.Value, error) {
+	var values []reflect.Value
+	err := g.transact(func(tg *graph) error {
+		var err error
+		values, err = tg.resolveDefinitions(tg.DefinitionsByAssignableType(ptrType))
+		return err
+	})
+	return values, err
+}
+
 // ResolveAll known pointers into values, caching and returning the results
 func (g *graph) ResolveAll() []reflect.Value {
+	values, err := g.TryResolveAll()
+	must(err)
+	return values
+}
+
+// TryResolveAll known pointers into values, like ResolveAll, but returns an error instead of panicking
+func (g *graph) TryResolveAll() ([]reflect.Value, error) {
+	var values []reflect.Value
+	err := g.transact(func(tg *graph) error {
+		var err error
+		values, err = tg.resolveDefinitions(tg.snapshot())
+		return err
+	})
+	return values, err
+}
+
+// DefinitionsByType returns all the definitions whose pointer has the exact specified type, without resolving them
+func (g *graph) DefinitionsByType(ptrType reflect.Type) []Definition {
+	return g.definitionsWhere(func(def Definition) bool {
+		return reflect.TypeOf(def.Ptr()).Elem() == ptrType
+	})
+}
+
+// DefinitionsByAssignableType returns all the definitions whose pointer is assignable to the specified type,
+// without resolving them
+func (g *graph) DefinitionsByAssignableType(ptrType reflect.Type) []Definition {
+	return g.definitionsWhere(func(def Definition) bool {
+		return reflect.TypeOf(def.Ptr()).Elem().AssignableTo(ptrType)
+	})
+}
+
+func (g *graph) resolveDefinitions(defs []Definition) ([]reflect.Value, error) {
 	var values []reflect.Value
-	for _, def := range g.definitions {
-		values = append(values, def.Resolve(g))
+	for _, def := range defs {
+		value, err := g.TryResolve(def.Ptr())
+		if err != nil {
+			return nil, err
+		}
+		values = append(values, value)
 	}
-	return values
+	return values, nil
 }
 
-// Finalize obscures (finalizes) all the resolved definitions
+// Finalize obscures (finalizes) all the resolved definitions, in the reverse of the order they were resolved,
+// so that dependents are finalized before their dependencies.
+// Errors from FinalizableContext values are ignored. Use FinalizeContext to handle them.
 func (g *graph) Finalize() {
-	for _, def := range g.definitions {
-		def.Obscure(g)
+	_ = g.FinalizeContext(context.Background())
+}
+
+// FinalizeContext obscures (finalizes) all the resolved definitions, like Finalize, but passes the context to any
+// FinalizableContext values and returns the combined errors of every finalizer that failed
+func (g *graph) FinalizeContext(ctx context.Context) error {
+	g.mutex.Lock()
+	resolved := g.resolved
+	g.resolved = nil
+	g.resolvedSet = make(map[Definition]bool)
+	g.mutex.Unlock()
+
+	fg := g.withContext(ctx)
+	var errs []error
+	for i := len(resolved) - 1; i >= 0; i-- {
+		if err := resolved[i].obscure(fg); err != nil {
+			errs = append(errs, err)
+		}
+	}
+
+	// definitions resolved outside of the graph were not recorded
+	for _, def := range g.local() {
+		if err := def.Obscure(fg); err != nil {
+			errs = append(errs, err)
+		}
 	}
+	return errors.Join(errs...)
 }
 
 // String returns a multiline string representation of the dependency graph
-func (g graph) String() string {
+func (g *graph) String() string {
 	return fmt.Sprintf("&graph{\n%s\n}",
 		indent(fmt.Sprintf("definitions: %s", g.fmtDefinitions()), 1),
 	)
 }
 
-func (g graph) fmtDefinitions() string {
-	a := make([]string, 0, len(g.definitions))
-	for _, def := range g.definitions {
+func (g *graph) fmtDefinitions() string {
+	defs := g.local()
+	a := make([]string, 0, len(defs))
+	for _, def := range defs {
 		a = append(a, def.String())
 	}
 	sort.Strings(a)
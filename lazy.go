@@ -0,0 +1,91 @@
+package inject
+
+import (
+	"reflect"
+	"sync"
+)
+
+// Lazy is a constructor argument type that defers resolving a dependency until it is first called.
+// Providers supply Lazy[T] (and any other func() T or func() (T, error)) arguments as a function that resolves T,
+// which panics (or returns the error) if T cannot be resolved. The resolved value is cached after the first call.
+type Lazy[T any] func() T
+
+// isLazyType returns true if the type is a function that takes no arguments and returns a value,
+// or a value and an error
+func isLazyType(t reflect.Type) bool {
+	if t.Kind() != reflect.Func || t.NumIn() != 0 {
+		return false
+	}
+	switch t.NumOut() {
+	case 1:
+		return true
+	case 2:
+		return t.Out(1) == errorType
+	}
+	return false
+}
+
+// resolvesLazily returns true if a dependency is supplied by a function that resolves it when first called
+func resolvesLazily(g Graph, dep Dependency) bool {
+	if !dep.Lazy {
+		return false
+	}
+	if dep.Ptr != nil || dep.Name != "" {
+		return true
+	}
+	return len(g.DefinitionsByType(dep.Type)) == 0
+}
+
+// lazyDependency returns the dependency that a lazy dependency function resolves
+func lazyDependency(dep Dependency) Dependency {
+	dep.Type = dep.Type.Out(0)
+	dep.Lazy = false
+	dep.Multi = dep.Type.Kind() == reflect.Slice && dep.Ptr == nil
+	return dep
+}
+
+// deferral supplies lazy arguments to a constructor. Until the constructor's definition is resolved (including its
+// decorators and initializer), lazy arguments are resolved with the constructor's graph, so that cycles are detected.
+// Afterwards, they are resolved with a detached graph.
+type deferral struct {
+	g Graph
+}
+
+func newDeferral(g Graph) *deferral {
+	return &deferral{g: g}
+}
+
+func (d *deferral) graph() Graph {
+	if cg, ok := d.g.(*graph); ok && cg.frame != nil && cg.frame.finished() {
+		return detached(cg)
+	}
+	return d.g
+}
+
+// thunk returns a function of the specified lazy type that resolves its value when first called
+func (d *deferral) thunk(fnType reflect.Type, resolve func(Graph) (reflect.Value, error)) reflect.Value {
+	var (
+		mutex sync.Mutex
+		value reflect.Value
+	)
+	return reflect.MakeFunc(fnType, func([]reflect.Value) []reflect.Value {
+		mutex.Lock()
+		defer mutex.Unlock()
+
+		var err error
+		if !value.IsValid() {
+			value, err = resolve(d.graph())
+		}
+
+		if fnType.NumOut() == 1 {
+			if err != nil {
+				panic(err)
+			}
+			return []reflect.Value{value}
+		}
+		if err != nil {
+			return []reflect.Value{reflect.Zero(fnType.Out(0)), reflect.ValueOf(&err).Elem()}
+		}
+		return []reflect.Value{value, reflect.Zero(errorType)}
+	})
+}
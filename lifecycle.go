@@ -1,5 +1,10 @@
 package inject
 
+import (
+	"context"
+	"reflect"
+)
+
 // Initializable describes an object that needs initialization after being created
 type Initializable interface {
 	Initialize()
@@ -9,3 +14,55 @@ type Initializable interface {
 type Finalizable interface {
 	Finalize()
 }
+
+// InitializableContext describes an object that needs initialization after being created,
+// which may fail or be bounded by a context deadline
+type InitializableContext interface {
+	Initialize(ctx context.Context) error
+}
+
+// FinalizableContext describes an object that needs finalization before being destroyed,
+// which may fail or be bounded by a context deadline
+type FinalizableContext interface {
+	Finalize(ctx context.Context) error
+}
+
+// initialize calls the lifecycle initialization method of the value, if it has one
+func initialize(ctx context.Context, value reflect.Value) error {
+	switch obj := value.Interface().(type) {
+	case InitializableContext:
+		return obj.Initialize(ctx)
+	case Initializable:
+		obj.Initialize()
+	}
+	return nil
+}
+
+// finalize calls the lifecycle finalization method of the value, if it has one
+func finalize(ctx context.Context, value reflect.Value) error {
+	switch obj := value.Interface().(type) {
+	case FinalizableContext:
+		return obj.Finalize(ctx)
+	case Finalizable:
+		obj.Finalize()
+	}
+	return nil
+}
+
+// isFinalizable returns true if the value has a lifecycle finalization method
+func isFinalizable(value reflect.Value) bool {
+	switch value.Interface().(type) {
+	case FinalizableContext, Finalizable:
+		return true
+	}
+	return false
+}
+
+// isInitializable returns true if the value has a lifecycle initialization method
+func isInitializable(value reflect.Value) bool {
+	switch value.Interface().(type) {
+	case InitializableContext, Initializable:
+		return true
+	}
+	return false
+}
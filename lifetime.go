@@ -0,0 +1,27 @@
+package inject
+
+// Lifetime describes how long a resolved value is cached by its definition
+type Lifetime int
+
+const (
+	// Singleton definitions call their provider once and cache the result until finalized (the default)
+	Singleton Lifetime = iota
+	// Transient definitions call their provider every time they are resolved.
+	// Finalizable instances are tracked by the resolving graph until it is finalized,
+	// so long-lived graphs should resolve them through a scope.
+	Transient
+	// Scoped definitions call their provider once per scope
+	Scoped
+)
+
+func (l Lifetime) String() string {
+	switch l {
+	case Singleton:
+		return "singleton"
+	case Transient:
+		return "transient"
+	case Scoped:
+		return "scoped"
+	}
+	return "unknown"
+}
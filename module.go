@@ -0,0 +1,229 @@
+package inject
+
+import (
+	"fmt"
+	"reflect"
+)
+
+// Module is a reusable bundle of definitions that can be installed into a Graph, along with the modules it imports.
+// Definitions that a module does not export are private: they are hidden from lookups by type, name and tag, except
+// while resolving the module's own definitions. Private definitions can still be resolved by pointer.
+type Module interface {
+	Name() string
+	Imports() []Module
+	Definitions() []Definition
+	IsExported(def Definition) bool
+	fmt.Stringer
+}
+
+type module struct {
+	name        string
+	imports     []Module
+	definitions []Definition
+	// exported pointers and types; if both are empty, every definition is exported
+	exportPtrs  []interface{}
+	exportTypes []reflect.Type
+}
+
+// ModuleOption configures a Module
+type ModuleOption func(*module)
+
+// Include adds definitions to a module
+func Include(defs ...Definition) ModuleOption {
+	return func(m *module) {
+		m.definitions = append(m.definitions, defs...)
+	}
+}
+
+// Import specifies modules that are installed before the module
+func Import(mods ...Module) ModuleOption {
+	return func(m *module) {
+		m.imports = append(m.imports, mods...)
+	}
+}
+
+// Export specifies pointers of definitions that are visible outside the module
+func Export(ptrs ...interface{}) ModuleOption {
+	return func(m *module) {
+		m.exportPtrs = append(m.exportPtrs, ptrs...)
+	}
+}
+
+// ExportType specifies types whose assignable definitions are visible outside the module
+func ExportType(types ...reflect.Type) ModuleOption {
+	return func(m *module) {
+		m.exportTypes = append(m.exportTypes, types...)
+	}
+}
+
+// NewModule constructs a new Module. If no exports are specified, every definition of the module is exported.
+func NewModule(name string, opts ...ModuleOption) Module {
+	m := &module{
+		name: name,
+	}
+	for _, opt := range opts {
+		opt(m)
+	}
+
+	for _, ptr := range m.exportPtrs {
+		if !m.includes(ptr) {
+			panic(fmt.Sprintf("exported pointer (%s) must be defined by module %q", ptrString(ptr), name))
+		}
+	}
+	return m
+}
+
+func (m *module) Name() string {
+	return m.name
+}
+
+func (m *module) Imports() []Module {
+	return m.imports
+}
+
+func (m *module) Definitions() []Definition {
+	return m.definitions
+}
+
+// IsExported returns true if the definition is visible outside the module
+func (m *module) IsExported(def Definition) bool {
+	if len(m.exportPtrs) == 0 && len(m.exportTypes) == 0 {
+		return true
+	}
+	for _, ptr := range m.exportPtrs {
+		if ptr == def.Ptr() {
+			return true
+		}
+	}
+	for _, t := range m.exportTypes {
+		if reflect.TypeOf(def.Ptr()).Elem().AssignableTo(t) {
+			return true
+		}
+	}
+	return false
+}
+
+func (m *module) includes(ptr interface{}) bool {
+	for _, def := range m.definitions {
+		if def.Ptr() == ptr {
+			return true
+		}
+	}
+	return false
+}
+
+func (m *module) String() string {
+	imports := make([]string, len(m.imports), len(m.imports))
+	for i, imp := range m.imports {
+		imports[i] = fmt.Sprintf("%q", imp.Name())
+	}
+	defs := make([]string, len(m.definitions), len(m.definitions))
+	for i, def := range m.definitions {
+		defs[i] = definitionLabel(def)
+		if !m.IsExported(def) {
+			defs[i] += " private"
+		}
+	}
+	return fmt.Sprintf("&module{\n%s,\n%s,\n%s\n}",
+		indent(fmt.Sprintf("name: %q", m.name), 1),
+		indent(fmt.Sprintf("imports: %s", arrayString(imports)), 1),
+		indent(fmt.Sprintf("definitions: %s", arrayString(defs)), 1),
+	)
+}
+
+// Install adds the definitions of a module, and the modules it imports, to the graph.
+// Modules already installed in the graph (or its parents) are skipped.
+// Panics if any of the definitions has the same pointer as another definition, without installing any of them.
+func (g *graph) Install(mod Module) {
+	must(g.TryInstall(mod))
+}
+
+// TryInstall installs a module, like Install, but returns an ErrDuplicate instead of panicking
+func (g *graph) TryInstall(mod Module) error {
+	g.mutex.Lock()
+	defer g.mutex.Unlock()
+
+	// collect under the same lock that installs, so that concurrent installs of a module are deduplicated
+	var mods []Module
+	g.collectModules(mod, make(map[Module]bool), &mods)
+
+	// check every definition before adding any of them
+	batch := make(map[interface{}]Definition)
+	for _, m := range mods {
+		for _, def := range m.Definitions() {
+			existing, found := g.definitions[def.Ptr()]
+			if !found {
+				existing, found = batch[def.Ptr()]
+			}
+			if found {
+				return fmt.Errorf("failed to install module %q: %w", m.Name(), ErrDuplicate{Definition: def, Existing: existing})
+			}
+			batch[def.Ptr()] = def
+		}
+	}
+
+	for _, m := range mods {
+		for _, def := range m.Definitions() {
+			must(g.add(def, false))
+			g.modules[def] = m
+		}
+		g.installed[m] = true
+	}
+	return nil
+}
+
+// collectModules appends the modules that need installing, imports first, skipping those already installed.
+// The caller must hold the lock.
+func (g *graph) collectModules(mod Module, seen map[Module]bool, mods *[]Module) {
+	if seen[mod] || g.isInstalled(mod) {
+		return
+	}
+	seen[mod] = true
+	for _, imp := range mod.Imports() {
+		g.collectModules(imp, seen, mods)
+	}
+	*mods = append(*mods, mod)
+}
+
+// isInstalled returns true if the module is installed in the graph or its parents. The caller must hold the lock.
+func (g *graph) isInstalled(mod Module) bool {
+	if g.installed[mod] {
+		return true
+	}
+	for state := g.parent; state != nil; state = state.parent {
+		state.mutex.RLock()
+		installed := state.installed[mod]
+		state.mutex.RUnlock()
+		if installed {
+			return true
+		}
+	}
+	return false
+}
+
+// moduleOf returns the module that installed a definition in the graph or its parents, if any
+func (g *graph) moduleOf(def Definition) Module {
+	for state := g.graphState; state != nil; state = state.parent {
+		state.mutex.RLock()
+		mod, found := state.modules[def]
+		state.mutex.RUnlock()
+		if found {
+			return mod
+		}
+	}
+	return nil
+}
+
+// within returns a copy of the graph that can see the private definitions of a module, if any
+func (g *graph) within(mod Module) *graph {
+	next := *g
+	next.module = mod
+	return &next
+}
+
+// visible returns true if a definition installed in the graph state can be found by type, name or tag.
+// The caller must hold the lock.
+func (g *graph) visible(state *graphState, def Definition) bool {
+	mod, found := state.modules[def]
+	return !found || mod == g.module || mod.IsExported(def)
+}
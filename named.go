@@ -0,0 +1,127 @@
+package inject
+
+import (
+	"reflect"
+)
+
+// ResolveNamed resolves the definition with the specified name into a value
+func (g *graph) ResolveNamed(name string) reflect.Value {
+	value, err := g.TryResolveNamed(name)
+	must(err)
+	return value
+}
+
+// TryResolveNamed resolves the definition with the specified name into a value, like ResolveNamed,
+// but returns an error instead of panicking
+func (g *graph) TryResolveNamed(name string) (reflect.Value, error) {
+	def, err := definitionNamed(g, name)
+	if err != nil {
+		return reflect.Value{}, err
+	}
+	return g.TryResolve(def.Ptr())
+}
+
+// ResolveByTag resolves all the definitions with the specified tag into a list of values
+func (g *graph) ResolveByTag(tag string) []reflect.Value {
+	values, err := g.TryResolveByTag(tag)
+	must(err)
+	return values
+}
+
+// TryResolveByTag resolves all the definitions with the specified tag into a list of values, like ResolveByTag,
+// but returns an error instead of panicking
+func (g *graph) TryResolveByTag(tag string) ([]reflect.Value, error) {
+	var values []reflect.Value
+	err := g.transact(func(tg *graph) error {
+		var err error
+		values, err = tg.resolveDefinitions(tg.DefinitionsByTag(tag))
+		return err
+	})
+	return values, err
+}
+
+// DefinitionsByName returns the definitions with the specified name, without resolving them.
+// Names defined in a scope shadow the same names defined in its parents.
+func (g *graph) DefinitionsByName(name string) []Definition {
+	for state := g.graphState; state != nil; state = state.parent {
+		var defs []Definition
+		state.mutex.RLock()
+		for _, def := range state.ordered() {
+			if def.Name() == name && g.visible(state, def) {
+				defs = append(defs, def)
+			}
+		}
+		state.mutex.RUnlock()
+		if len(defs) > 0 {
+			return defs
+		}
+	}
+	return nil
+}
+
+// DefinitionsByTag returns the definitions with the specified tag, without resolving them
+func (g *graph) DefinitionsByTag(tag string) []Definition {
+	return g.definitionsWhere(func(def Definition) bool {
+		for _, t := range def.Tags() {
+			if t == tag {
+				return true
+			}
+		}
+		return false
+	})
+}
+
+// definitionNamed returns the one definition with the specified name
+func definitionNamed(g Graph, name string) (Definition, error) {
+	defs := g.DefinitionsByName(name)
+	if len(defs) > 1 {
+		return nil, ErrAmbiguous{Name: name, Candidates: defs}
+	} else if len(defs) == 0 {
+		return nil, ErrNoMatch{Name: name}
+	}
+	return defs[0], nil
+}
+
+// FindByTag resolves all defined pointers with the specified tag that are assignable to the type of the supplied
+// slice and appends the resolved values to the slice.
+func FindByTag(g Graph, tag string, listPtr interface{}) []reflect.Value {
+	values, err := TryFindByTag(g, tag, listPtr)
+	must(err)
+	return values
+}
+
+// TryFindByTag resolves all defined pointers with the specified tag, like FindByTag,
+// but returns an error instead of panicking.
+func TryFindByTag(g Graph, tag string, listPtr interface{}) ([]reflect.Value, error) {
+	ptrType := reflect.TypeOf(listPtr)
+	if ptrType == nil || ptrType.Kind() != reflect.Ptr || ptrType.Elem().Kind() != reflect.Slice {
+		return nil, ErrNotPointer{Type: ptrType, Slice: true}
+	}
+
+	// resolve every match in one transaction, so that a failure rolls back the earlier matches too
+	elemType := ptrType.Elem().Elem()
+	var values []reflect.Value
+	err := transactional(g, func(tg Graph) error {
+		for _, def := range tg.DefinitionsByTag(tag) {
+			if !reflect.TypeOf(def.Ptr()).Elem().AssignableTo(elemType) {
+				continue
+			}
+			value, err := tg.TryResolve(def.Ptr())
+			if err != nil {
+				return err
+			}
+			values = append(values, value)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	listValue := reflect.Append(reflect.ValueOf(listPtr).Elem(), values...)
+
+	// update the listPtr value
+	reflect.ValueOf(listPtr).Elem().Set(listValue)
+
+	return values, nil
+}
@@ -0,0 +1,225 @@
+package inject
+
+import (
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+	"sync"
+	"text/tabwriter"
+	"time"
+)
+
+// Observer is notified as a graph resolves, initializes and finalizes definitions.
+// Observers are called synchronously, possibly from multiple goroutines, so they must be fast and safe for concurrent
+// use. Resolve durations include the time spent resolving dependencies and initializing the value.
+type Observer interface {
+	// OnResolveStart is called before the provider of a definition is called
+	OnResolveStart(def Definition)
+	// OnResolveEnd is called after the provider of a definition returns and the value is initialized
+	OnResolveEnd(def Definition, duration time.Duration, err error)
+	// OnInitialize is called after the value of a definition is initialized, if it is Initializable
+	OnInitialize(def Definition, duration time.Duration, err error)
+	// OnFinalize is called after the value of a definition is finalized, if it is Finalizable
+	OnFinalize(def Definition, duration time.Duration, err error)
+}
+
+// SelfObserver is an Observer that is also notified of the exclusive (self) duration of each resolution,
+// which excludes the time spent resolving dependencies, so that slow constructors can be told apart from their
+// dependents. It is called before OnResolveEnd.
+type SelfObserver interface {
+	Observer
+	OnResolveSelf(def Definition, duration time.Duration)
+}
+
+// Observe registers an observer that is notified of events in the graph and its scopes
+func (g *graph) Observe(o Observer) {
+	g.mutex.Lock()
+	defer g.mutex.Unlock()
+	g.observers = append(g.observers, o)
+}
+
+// observersOf returns the observers of a graph and its parents
+func observersOf(g Graph) []Observer {
+	cg, ok := g.(*graph)
+	if !ok {
+		return nil
+	}
+	var observers []Observer
+	for state := cg.graphState; state != nil; state = state.parent {
+		state.mutex.RLock()
+		observers = append(observers, state.observers...)
+		state.mutex.RUnlock()
+	}
+	return observers
+}
+
+// Timing describes how long a definition took to resolve, initialize and finalize.
+// Transient definitions accumulate the durations of every resolution.
+type Timing struct {
+	Definition Definition
+	// Count is the number of times the provider was called
+	Count   int
+	Resolve time.Duration
+	// Self is the part of Resolve that was not spent resolving dependencies
+	Self       time.Duration
+	Initialize time.Duration
+	Finalize   time.Duration
+	// Err is the last error returned while resolving, initializing or finalizing the definition, if any
+	Err error
+}
+
+// Recorder is an Observer that records the timing of each definition, to help find slow constructors
+type Recorder struct {
+	mutex   sync.Mutex
+	timings map[Definition]*Timing
+	// order the definitions started resolving
+	order []Definition
+}
+
+// NewRecorder constructs a new Recorder, which must be registered with Graph.Observe
+func NewRecorder() *Recorder {
+	return &Recorder{
+		timings: make(map[Definition]*Timing),
+	}
+}
+
+// timing returns the timing of a definition, creating it if necessary. The caller must hold the lock.
+func (r *Recorder) timing(def Definition) *Timing {
+	t, found := r.timings[def]
+	if !found {
+		t = &Timing{Definition: def}
+		r.timings[def] = t
+		r.order = append(r.order, def)
+	}
+	return t
+}
+
+func (r *Recorder) OnResolveStart(def Definition) {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+	r.timing(def).Count++
+}
+
+func (r *Recorder) OnResolveEnd(def Definition, duration time.Duration, err error) {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+	t := r.timing(def)
+	t.Resolve += duration
+	if err != nil {
+		t.Err = err
+	}
+}
+
+func (r *Recorder) OnResolveSelf(def Definition, duration time.Duration) {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+	r.timing(def).Self += duration
+}
+
+func (r *Recorder) OnInitialize(def Definition, duration time.Duration, err error) {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+	t := r.timing(def)
+	t.Initialize += duration
+	if err != nil {
+		t.Err = err
+	}
+}
+
+func (r *Recorder) OnFinalize(def Definition, duration time.Duration, err error) {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+	t := r.timing(def)
+	t.Finalize += duration
+	if err != nil {
+		t.Err = err
+	}
+}
+
+// Timings returns the recorded timings, in the order the definitions started resolving
+func (r *Recorder) Timings() []Timing {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+	timings := make([]Timing, len(r.order), len(r.order))
+	for i, def := range r.order {
+		timings[i] = *r.timings[def]
+	}
+	return timings
+}
+
+// Report returns a table of the recorded timings, slowest first, by the time spent resolving each definition
+// excluding its dependencies
+func (r *Recorder) Report() string {
+	timings := r.Timings()
+	sort.SliceStable(timings, func(i, j int) bool {
+		return timings[i].Self > timings[j].Self
+	})
+
+	var b strings.Builder
+	w := tabwriter.NewWriter(&b, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "RESOLVE\tSELF\tINITIALIZE\tFINALIZE\tCOUNT\tDEFINITION")
+	for _, t := range timings {
+		label := definitionLabel(t.Definition)
+		if t.Err != nil {
+			label += " (failed)"
+		}
+		fmt.Fprintf(w, "%v\t%v\t%v\t%v\t%d\t%s\n", t.Resolve, t.Self, t.Initialize, t.Finalize, t.Count, label)
+	}
+	w.Flush()
+	return b.String()
+}
+
+// observeResolve notifies observers that a definition started resolving,
+// and returns a function to notify them that it finished
+func observeResolve(g Graph, def Definition) func(err error) {
+	observers := observersOf(g)
+	if len(observers) == 0 {
+		return func(error) {}
+	}
+	for _, o := range observers {
+		o.OnResolveStart(def)
+	}
+	start := time.Now()
+	return func(err error) {
+		duration := time.Since(start)
+		self := duration
+		if cg, ok := g.(*graph); ok && cg.frame != nil {
+			self = cg.frame.exclusive(duration)
+		}
+		for _, o := range observers {
+			if so, ok := o.(SelfObserver); ok {
+				so.OnResolveSelf(def, self)
+			}
+			o.OnResolveEnd(def, duration, err)
+		}
+	}
+}
+
+// initializeObserved initializes the value of a definition, if it is Initializable, and notifies observers
+func initializeObserved(g Graph, def Definition, value reflect.Value) error {
+	if !isInitializable(value) {
+		return nil
+	}
+	start := time.Now()
+	err := initialize(contextOf(g), value)
+	duration := time.Since(start)
+	for _, o := range observersOf(g) {
+		o.OnInitialize(def, duration, err)
+	}
+	return err
+}
+
+// finalizeObserved finalizes the value of a definition, if it is Finalizable, and notifies observers
+func finalizeObserved(g Graph, def Definition, value reflect.Value) error {
+	if !isFinalizable(value) {
+		return nil
+	}
+	start := time.Now()
+	err := finalize(contextOf(g), value)
+	duration := time.Since(start)
+	for _, o := range observersOf(g) {
+		o.OnFinalize(def, duration, err)
+	}
+	return err
+}
@@ -0,0 +1,127 @@
+package inject
+
+import (
+	"reflect"
+)
+
+// ResolveAllParallel resolves all known pointers into values, like ResolveAll, but uses up to the specified number of
+// workers to construct independent definitions concurrently. Dependencies are always resolved before their dependents.
+func (g *graph) ResolveAllParallel(workers int) []reflect.Value {
+	values, err := g.TryResolveAllParallel(workers)
+	must(err)
+	return values
+}
+
+// TryResolveAllParallel resolves all known pointers into values, like ResolveAllParallel,
+// but returns an error instead of panicking
+func (g *graph) TryResolveAllParallel(workers int) ([]reflect.Value, error) {
+	var values []reflect.Value
+	err := g.transact(func(tg *graph) error {
+		var err error
+		values, err = tg.resolveAllParallel(workers)
+		return err
+	})
+	return values, err
+}
+
+func (g *graph) resolveAllParallel(workers int) ([]reflect.Value, error) {
+	if workers < 1 {
+		workers = 1
+	}
+
+	defs := g.snapshot()
+	index := make(map[Definition]int, len(defs))
+	for i, def := range defs {
+		index[def] = i
+	}
+
+	// build the dependency DAG from the provider dependencies
+	waiting := make([]int, len(defs))
+	dependents := make([][]int, len(defs))
+	for i, def := range defs {
+		seen := make(map[int]bool)
+		mg := g.within(g.moduleOf(def))
+		for _, dep := range mg.definitionDependencies(def) {
+			if resolvesLazily(mg, dep) {
+				continue
+			}
+			for _, depDef := range mg.dependencyDefinitions(dep) {
+				j, found := index[depDef]
+				if !found || seen[j] {
+					continue
+				}
+				seen[j] = true
+				waiting[i]++
+				dependents[j] = append(dependents[j], i)
+			}
+		}
+	}
+
+	type result struct {
+		i     int
+		value reflect.Value
+		err   error
+	}
+
+	work := make(chan int, len(defs))
+	results := make(chan result, len(defs))
+	defer close(work)
+	for w := 0; w < workers; w++ {
+		go func() {
+			for i := range work {
+				value, err := g.TryResolve(defs[i].Ptr())
+				results <- result{i: i, value: value, err: err}
+			}
+		}()
+	}
+
+	inFlight := 0
+	for i := range defs {
+		if waiting[i] == 0 {
+			work <- i
+			inFlight++
+		}
+	}
+
+	values := make([]reflect.Value, len(defs))
+	resolved := make([]bool, len(defs))
+	var firstErr error
+	for inFlight > 0 {
+		r := <-results
+		inFlight--
+		if r.err != nil {
+			if firstErr == nil {
+				firstErr = r.err
+			}
+			continue
+		}
+		values[r.i] = r.value
+		resolved[r.i] = true
+		if firstErr != nil {
+			// stop scheduling, but wait for in-flight workers
+			continue
+		}
+		for _, d := range dependents[r.i] {
+			waiting[d]--
+			if waiting[d] == 0 {
+				work <- d
+				inFlight++
+			}
+		}
+	}
+	if firstErr != nil {
+		return nil, firstErr
+	}
+
+	// definitions left waiting are part of a cycle, which serial resolution reports
+	for i, def := range defs {
+		if !resolved[i] {
+			value, err := g.TryResolve(def.Ptr())
+			if err != nil {
+				return nil, err
+			}
+			values[i] = value
+		}
+	}
+	return values, nil
+}
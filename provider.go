@@ -10,38 +10,37 @@ type provider struct {
 	argPtrs     []interface{}
 }
 
-// NewProvider specifies how to construct a value given its constructor function and argument pointers
+// NewProvider specifies how to construct a value given its constructor function and argument pointers.
+// An argument of type Lazy[T] (or any func() T) may be supplied by a pointer to a T, which is resolved when the
+// argument is first called.
+// The constructor may optionally return an error as its second return value.
 func NewProvider(constructor interface{}, argPtrs ...interface{}) Provider {
-	fnValue := reflect.ValueOf(constructor)
-	if fnValue.Kind() != reflect.Func {
-		panic(fmt.Sprintf("constructor (%v) is not a function, found %v", fnValue, fnValue.Kind()))
-	}
+	validateConstructor(constructor)
 
+	fnValue := reflect.ValueOf(constructor)
 	fnType := reflect.TypeOf(constructor)
-	if fnType.NumOut() != 1 {
-		panic(fmt.Sprintf("constructor must have exactly 1 return value, found %v", fnType.NumOut()))
-	}
 
 	argCount := fnType.NumIn()
 	if !fnValue.Type().IsVariadic() && argCount != len(argPtrs) {
 		panic(fmt.Sprintf("argPtrs (%d) must match constructor arguments (%d)", len(argPtrs), argCount))
 	}
 
-	var kind reflect.Kind
+	var inType reflect.Type
 	for i, argPtr := range argPtrs {
 		isVariadic := fnValue.Type().IsVariadic() && (fnType.NumIn() == 1 || i >= fnType.NumIn())
 
 		if i < fnType.NumIn() {
-			kind = fnType.In(i).Kind()
+			inType = fnType.In(i)
 		} else {
-			kind = fnType.In(fnType.NumIn() - 1).Kind()
+			inType = fnType.In(fnType.NumIn() - 1)
 		}
 
 		if reflect.TypeOf(argPtr).Kind() != reflect.Ptr {
 			panic(fmt.Sprintf("argPtrs must all be pointers, found %v", reflect.TypeOf(argPtr)))
 		}
 
-		if !isVariadic && reflect.ValueOf(argPtr).Elem().Kind() != kind {
+		kind := reflect.ValueOf(argPtr).Elem().Kind()
+		if !isVariadic && kind != inType.Kind() && !(isLazyType(inType) && kind == inType.Out(0).Kind()) {
 			panic("argPtrs must match constructor argument types")
 		}
 	}
@@ -53,44 +52,67 @@ func NewProvider(constructor interface{}, argPtrs ...interface{}) Provider {
 }
 
 // Provide returns the result of executing the constructor with argument values resolved from a dependency graph
-func (p provider) Provide(g Graph) reflect.Value {
-	fnType := reflect.TypeOf(p.constructor)
+func (p provider) Provide(g Graph) (reflect.Value, error) {
+	lazy := newDeferral(g)
+
+	deps := p.Dependencies()
+	args := make([]reflect.Value, len(deps), len(deps))
+	for i, dep := range deps {
+		if dep.Lazy {
+			args[i] = lazy.thunk(dep.Type, func(g Graph) (reflect.Value, error) {
+				return resolvePtr(g, dep.Ptr, dep.Type.Out(0))
+			})
+			continue
+		}
 
-	argCount := fnType.NumIn()
-	if fnType.IsVariadic() {
-		argCount = len(p.argPtrs)
+		arg, err := resolvePtr(g, dep.Ptr, dep.Type)
+		if err != nil {
+			return reflect.Value{}, fmt.Errorf("failed to resolve provider argument %d: %w", i, err)
+		}
+		args[i] = arg
 	}
 
-	args := make([]reflect.Value, argCount, argCount)
-	var inType reflect.Type
-	for i := 0; i < argCount; i++ {
-		arg := g.Resolve(p.argPtrs[i])
-		argType := arg.Type()
+	return callConstructor(p.constructor, args)
+}
+
+// resolvePtr resolves an argument pointer and converts the value to the constructor argument type
+func resolvePtr(g Graph, ptr interface{}, inType reflect.Type) (reflect.Value, error) {
+	arg, err := g.TryResolve(ptr)
+	if err != nil {
+		return reflect.Value{}, err
+	}
+	return convertArg(arg, inType)
+}
+
+// Type returns the type of value to expect from Provide
+func (p provider) ReturnType() reflect.Type {
+	return reflect.TypeOf(p.constructor).Out(0)
+}
 
+// Dependencies returns the argument pointers of the constructor, in argument order
+func (p provider) Dependencies() []Dependency {
+	fnType := reflect.TypeOf(p.constructor)
+	deps := make([]Dependency, len(p.argPtrs), len(p.argPtrs))
+	for i, argPtr := range p.argPtrs {
+		var inType reflect.Type
 		if fnType.IsVariadic() && i >= fnType.NumIn()-1 {
 			inType = fnType.In(fnType.NumIn() - 1).Elem()
 		} else {
 			inType = fnType.In(i)
 		}
-
-		if !argType.AssignableTo(inType) {
-			if !argType.ConvertibleTo(inType) {
-				panic(fmt.Sprintf(
-					"arg %d of type %q cannot be assigned or converted to type %q for provider constructor (%s)",
-					i, argType, inType, p.constructor,
-				))
-			}
-			arg = arg.Convert(inType)
-		}
-		args[i] = arg
+		argType := reflect.TypeOf(argPtr).Elem()
+		lazy := isLazyType(inType) && !argType.AssignableTo(inType) && !argType.ConvertibleTo(inType)
+		deps[i] = Dependency{Ptr: argPtr, Type: inType, Lazy: lazy}
 	}
+	return deps
+}
 
-	return reflect.ValueOf(p.constructor).Call(args)[0]
+func (p provider) kind() string {
+	return "provider"
 }
 
-// Type returns the type of value to expect from Provide
-func (p provider) ReturnType() reflect.Type {
-	return reflect.TypeOf(p.constructor).Out(0)
+func (p provider) constructorType() reflect.Type {
+	return reflect.TypeOf(p.constructor)
 }
 
 // String returns a multiline string representation of the provider
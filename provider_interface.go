@@ -8,6 +8,60 @@ import (
 // Provider describes how to retrieve (or construct) a generic value, given a dependency graph.
 type Provider interface {
 	ReturnType() reflect.Type
-	Provide(Graph) reflect.Value
+	Provide(Graph) (reflect.Value, error)
 	fmt.Stringer
 }
+
+// Dependent describes a Provider that can list its dependencies without being called,
+// allowing a Graph to be inspected before it is resolved.
+type Dependent interface {
+	Dependencies() []Dependency
+}
+
+// Dependency describes a provider argument and how it is resolved from a Graph
+type Dependency struct {
+	// Ptr is the pointer resolved to supply the argument, or nil if the argument is resolved by type
+	Ptr interface{}
+	// Type is the type of the argument
+	Type reflect.Type
+	// Name is the name of the definition resolved to supply the argument, if it is resolved by name
+	Name string
+	// Assignable is true if the argument is resolved by assignable type, rather than exact type
+	Assignable bool
+	// Multi is true if the argument is a slice of every definition assignable to the element type,
+	// unless a definition matches the slice type itself
+	Multi bool
+	// Lazy is true if the argument is a function (ex: Lazy[T]) that resolves the dependency when first called,
+	// unless a definition matches the function type itself
+	Lazy bool
+	// Optional is true if the argument is the zero value when no definition can supply it
+	Optional bool
+	// Field is the name of the struct field that receives the argument, if it is injected into a field
+	Field string
+}
+
+// constructed describes a Provider that calls a constructor function
+type constructed interface {
+	constructorType() reflect.Type
+}
+
+// providerSignature returns the constructor type of a provider, if it has one, or its return type otherwise
+func providerSignature(p Provider) string {
+	if c, ok := p.(constructed); ok {
+		return c.constructorType().String()
+	}
+	return p.ReturnType().String()
+}
+
+// kinded describes a Provider of this package, which has a short name for its kind
+type kinded interface {
+	kind() string
+}
+
+// providerKind returns the short name for the kind of a provider, or its type for custom providers
+func providerKind(p Provider) string {
+	if k, ok := p.(kinded); ok {
+		return k.kind()
+	}
+	return reflect.TypeOf(p).String()
+}
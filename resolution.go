@@ -0,0 +1,103 @@
+package inject
+
+import (
+	"fmt"
+	"reflect"
+)
+
+// resolution records a newly resolved definition, so that it can be finalized later.
+// Transient definitions are recorded once per instance, with the instance value.
+type resolution struct {
+	def   Definition
+	value reflect.Value
+	// state of the graph that recorded the resolution
+	state *graphState
+}
+
+// obscure obscures (finalizes) the resolved definition, or finalizes the transient instance
+func (r *resolution) obscure(g *graph) error {
+	if !r.value.IsValid() {
+		return r.def.Obscure(g)
+	}
+	err := finalizeObserved(g, r.def, r.value)
+	if err != nil {
+		return fmt.Errorf("failed to finalize %v: %w", r.value.Type(), err)
+	}
+	return nil
+}
+
+// release rolls back the resolution for a failed transaction, like obscure, unless the value was also received by
+// another transaction that has not rolled back, and returns true if the resolution was kept
+func (r *resolution) release(g *graph, tx *transaction) (bool, error) {
+	if d, ok := r.def.(*definition); ok && !r.value.IsValid() {
+		return d.release(g, tx, r)
+	}
+	return false, r.obscure(g)
+}
+
+// recordResolved appends a newly resolved definition to the resolution order and the current transaction.
+// Transient instances are only recorded if they need finalization, and are kept until the graph is finalized.
+func (g *graph) recordResolved(def Definition, value reflect.Value) *resolution {
+	r := &resolution{def: def, state: g.graphState}
+	if def.Lifetime() == Transient {
+		if !isFinalizable(value) {
+			return nil
+		}
+		r.value = value
+	}
+
+	g.mutex.Lock()
+	if r.value.IsValid() {
+		g.resolved = append(g.resolved, r)
+	} else if !g.resolvedSet[def] {
+		g.resolvedSet[def] = true
+		g.resolved = append(g.resolved, r)
+	}
+	g.mutex.Unlock()
+
+	if g.tx != nil {
+		g.tx.record(r)
+	}
+	return r
+}
+
+// recordResolved tells the graph that resolved a definition that a value was newly constructed,
+// and returns the record of the resolution, if the graph keeps one
+func recordResolved(g Graph, def Definition, value reflect.Value) *resolution {
+	if cg, ok := g.(*graph); ok {
+		return cg.recordResolved(def, value)
+	}
+	return nil
+}
+
+// forgetResolved removes rolled back resolutions from the resolution order of the graphs that recorded them
+func forgetResolved(rs []*resolution) {
+	byState := make(map[*graphState][]*resolution)
+	for _, r := range rs {
+		byState[r.state] = append(byState[r.state], r)
+	}
+	for state, stateRs := range byState {
+		state.forgetResolved(stateRs)
+	}
+}
+
+func (g *graphState) forgetResolved(rs []*resolution) {
+	g.mutex.Lock()
+	defer g.mutex.Unlock()
+	removed := make(map[*resolution]bool, len(rs))
+	removedDefs := make(map[Definition]bool, len(rs))
+	for _, r := range rs {
+		removed[r] = true
+		if !r.value.IsValid() {
+			removedDefs[r.def] = true
+			delete(g.resolvedSet, r.def)
+		}
+	}
+	resolved := g.resolved[:0]
+	for _, r := range g.resolved {
+		if !removed[r] && !(removedDefs[r.def] && !r.value.IsValid()) {
+			resolved = append(resolved, r)
+		}
+	}
+	g.resolved = resolved
+}
@@ -0,0 +1,82 @@
+package inject
+
+import (
+	"sync"
+)
+
+// resolver identifies a chain of nested resolutions, so that a definition that is being resolved by one chain can
+// detect when waiting for it would deadlock, because its owner is (transitively) waiting for the waiting chain
+type resolver struct {
+	// owner of the definition the resolver is waiting for, if any
+	waitFor *resolver
+	// path of the resolver while waiting, ending with the definition it is waiting for
+	waitPath []Definition
+}
+
+// waits guards the waitFor and waitPath of every resolver
+var waits sync.Mutex
+
+// resolverOf returns the resolver of the graph's current resolution, or a new resolver
+func resolverOf(g Graph) *resolver {
+	if cg, ok := g.(*graph); ok && cg.resolver != nil {
+		return cg.resolver
+	}
+	return &resolver{}
+}
+
+// pathOf returns the path of definitions currently being resolved by the graph
+func pathOf(g Graph) []Definition {
+	if cg, ok := g.(*graph); ok {
+		return cg.path
+	}
+	return nil
+}
+
+// wait records that the resolver is waiting for a definition owned by another resolver,
+// or returns an ErrCycle if the owner is (transitively) waiting for this resolver
+func (r *resolver) wait(owner *resolver, path []Definition) error {
+	waits.Lock()
+	defer waits.Unlock()
+
+	var chain []*resolver
+	for o := owner; o != nil; o = o.waitFor {
+		if o == r {
+			return ErrCycle{Path: cyclePath(path, chain)}
+		}
+		chain = append(chain, o)
+	}
+
+	r.waitFor = owner
+	r.waitPath = path
+	return nil
+}
+
+// stopWaiting records that the resolver is no longer waiting
+func (r *resolver) stopWaiting() {
+	waits.Lock()
+	defer waits.Unlock()
+	r.waitFor = nil
+	r.waitPath = nil
+}
+
+// cyclePath joins the path of a resolver with the wait paths of the chain of resolvers it would wait for.
+// The last resolver in the chain is waiting for a definition in the path.
+func cyclePath(path []Definition, chain []*resolver) []Definition {
+	if len(chain) == 0 {
+		return append([]Definition{}, path...)
+	}
+	last := chain[len(chain)-1].waitPath
+	cycle := append([]Definition{}, pathFrom(path, last[len(last)-1])...)
+	for _, o := range chain {
+		cycle = append(cycle, pathFrom(o.waitPath, cycle[len(cycle)-1])[1:]...)
+	}
+	return cycle
+}
+
+// pathFrom returns the end of the path, starting with the definition, or the whole path if it is not found
+func pathFrom(path []Definition, def Definition) []Definition {
+	if i := indexOf(path, def); i >= 0 {
+		return path[i:]
+	}
+	return path
+}
@@ -0,0 +1,114 @@
+package inject
+
+// NewScope constructs a child Graph that inherits the definitions of this graph.
+// Definitions added to the scope shadow inherited definitions with the same pointer.
+// Inherited singletons are resolved (and finalized) by the graph that defines them, inherited Scoped definitions are
+// resolved once per scope, and inherited Transient definitions are resolved by the scope.
+// Pointers that are also defined by a parent are shared with the parent and other scopes, so scopes return the values
+// of inherited Scoped and Transient definitions, and of definitions that shadow the parent, without setting the
+// pointers.
+// Finalizing the scope only finalizes the values resolved by the scope.
+func (g *graph) NewScope() Graph {
+	return &graph{
+		graphState: newGraphState(g.graphState),
+	}
+}
+
+// lookup returns the definition of a pointer and the graph state that defines it, searching parents in order
+func (g *graph) lookup(ptr interface{}) (Definition, *graphState, bool) {
+	for state := g.graphState; state != nil; state = state.parent {
+		state.mutex.RLock()
+		def, found := state.definitions[ptr]
+		state.mutex.RUnlock()
+		if found {
+			return def, state, true
+		}
+	}
+	return nil, nil, false
+}
+
+// definitionsWhere returns the visible definitions that match, including those inherited from parents,
+// unless they are shadowed. Inherited definitions come first, and each graph's definitions are in the order they
+// were added.
+func (g *graph) definitionsWhere(match func(def Definition) bool) []Definition {
+	return g.collect(match, false)
+}
+
+// collect returns the definitions that match, like definitionsWhere, optionally including private module definitions
+func (g *graph) collect(match func(def Definition) bool, private bool) []Definition {
+	var levels [][]Definition
+	shadowed := make(map[interface{}]bool)
+	for state := g.graphState; state != nil; state = state.parent {
+		var level []Definition
+		state.mutex.RLock()
+		for _, def := range state.ordered() {
+			if !shadowed[def.Ptr()] && (private || g.visible(state, def)) && match(def) {
+				level = append(level, def)
+			}
+		}
+		for _, ptr := range state.order {
+			shadowed[ptr] = true
+		}
+		state.mutex.RUnlock()
+		levels = append(levels, level)
+	}
+
+	var defs []Definition
+	for i := len(levels) - 1; i >= 0; i-- {
+		defs = append(defs, levels[i]...)
+	}
+	return defs
+}
+
+// local returns the definitions defined by the graph, including scoped instances, but not inherited definitions
+func (g *graph) local() []Definition {
+	g.mutex.RLock()
+	defer g.mutex.RUnlock()
+	return append(g.ordered(), g.scopedOrder...)
+}
+
+// scopedDefinition returns the scope's instance of an inherited Scoped definition, creating it if necessary
+func (g *graph) scopedDefinition(def Definition) Definition {
+	g.mutex.Lock()
+	defer g.mutex.Unlock()
+	scoped, found := g.scoped[def]
+	if !found {
+		scoped = NewDefinition(def.Ptr(), def.Provider(), WithLifetime(Scoped), Named(def.Name()), Tagged(def.Tags()...))
+		g.scoped[def] = scoped
+		g.scopedOrder = append(g.scopedOrder, scoped)
+	}
+	return scoped
+}
+
+// ownsPointer returns true if resolving a definition with the graph may write the value to its pointer, because the
+// graph defines the pointer and none of its parents do. Otherwise, the pointer is shared with other graphs.
+func ownsPointer(g Graph, ptr interface{}) bool {
+	cg, ok := g.(*graph)
+	if !ok {
+		return true
+	}
+	_, owner, found := cg.lookup(ptr)
+	if !found {
+		return true
+	}
+	if owner != cg.graphState {
+		return false
+	}
+	for state := owner.parent; state != nil; state = state.parent {
+		state.mutex.RLock()
+		_, found := state.definitions[ptr]
+		state.mutex.RUnlock()
+		if found {
+			return false
+		}
+	}
+	return true
+}
+
+// in returns a copy of the graph that resolves definitions owned by another graph state,
+// preserving the resolution path, context and transaction
+func (g *graph) in(state *graphState) *graph {
+	next := *g
+	next.graphState = state
+	return &next
+}
@@ -16,6 +16,11 @@ func ptrString(ptr interface{}) string {
 	return fmt.Sprintf("%s=%p", reflect.TypeOf(ptr), ptr)
 }
 
+// definitionLabel returns a single line description of a definition's pointer type and provider constructor
+func definitionLabel(def Definition) string {
+	return fmt.Sprintf("%v (%s)", reflect.TypeOf(def.Ptr()), providerSignature(def.Provider()))
+}
+
 func mapString(m map[string]string) string {
 	if len(m) == 0 {
 		return "map[]"
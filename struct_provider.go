@@ -0,0 +1,143 @@
+package inject
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+)
+
+// tagName is the struct tag key that marks a field for injection
+const tagName = "inject"
+
+type structProvider struct {
+	template interface{}
+	fields   []Dependency
+}
+
+// NewStructProvider specifies how to construct a struct pointer given a template struct pointer.
+// Each provided value is a copy of the template with its tagged fields injected (see InjectFields).
+func NewStructProvider(structPtr interface{}) Provider {
+	fields, err := injectableFields(reflect.TypeOf(structPtr))
+	if err != nil {
+		panic(err.Error())
+	}
+
+	return structProvider{
+		template: structPtr,
+		fields:   fields,
+	}
+}
+
+// Provide returns a copy of the template struct pointer with its tagged fields resolved from a dependency graph
+func (p structProvider) Provide(g Graph) (reflect.Value, error) {
+	ptrValue := reflect.New(reflect.TypeOf(p.template).Elem())
+	ptrValue.Elem().Set(reflect.ValueOf(p.template).Elem())
+
+	err := injectFields(g, ptrValue, p.fields)
+	if err != nil {
+		return reflect.Value{}, err
+	}
+	return ptrValue, nil
+}
+
+// Type returns the type of value to expect from Provide
+func (p structProvider) ReturnType() reflect.Type {
+	return reflect.TypeOf(p.template)
+}
+
+// Dependencies returns the tagged fields of the struct, in field order
+func (p structProvider) Dependencies() []Dependency {
+	return p.fields
+}
+
+func (p structProvider) kind() string {
+	return "struct"
+}
+
+// String returns a multiline string representation of the structProvider
+func (p structProvider) String() string {
+	return fmt.Sprintf("&structProvider{\n%s\n}",
+		indent(fmt.Sprintf("type: %s", reflect.TypeOf(p.template)), 1),
+	)
+}
+
+// InjectFields resolves the fields of a struct pointer that are tagged with `inject:""`
+// (or `inject:"name=<name>"`) by finding exactly one defined pointer assignable to the field type
+// (or with the specified name) and assigning the resolved value to the field.
+// Fields tagged `inject:"optional"` (or `inject:"name=<name>,optional"`) are left unchanged when no defined pointer
+// can supply them.
+func InjectFields(g Graph, structPtr interface{}) {
+	must(TryInjectFields(g, structPtr))
+}
+
+// TryInjectFields resolves the tagged fields of a struct pointer, like InjectFields,
+// but returns an error instead of panicking
+func TryInjectFields(g Graph, structPtr interface{}) error {
+	fields, err := injectableFields(reflect.TypeOf(structPtr))
+	if err != nil {
+		return err
+	}
+	// resolve every field in one transaction, so that a failure rolls back the dependencies of the earlier fields too
+	return transactional(g, func(tg Graph) error {
+		return injectFields(tg, reflect.ValueOf(structPtr), fields)
+	})
+}
+
+// injectFields resolves the fields and assigns them, leaving the struct unchanged if any field fails to resolve
+func injectFields(g Graph, ptrValue reflect.Value, fields []Dependency) error {
+	values := make([]reflect.Value, len(fields))
+	for i, field := range fields {
+		if field.Optional && len(candidates(g, field)) == 0 {
+			continue
+		}
+		value, err := resolveArg(g, field)
+		if err != nil {
+			return fmt.Errorf("failed to resolve field %s: %w", field.Field, err)
+		}
+		values[i] = value
+	}
+
+	structValue := ptrValue.Elem()
+	for i, field := range fields {
+		if values[i].IsValid() {
+			structValue.FieldByName(field.Field).Set(values[i])
+		}
+	}
+	return nil
+}
+
+// injectableFields returns the fields of a struct pointer type that are tagged for injection
+func injectableFields(ptrType reflect.Type) ([]Dependency, error) {
+	if ptrType == nil || ptrType.Kind() != reflect.Ptr || ptrType.Elem().Kind() != reflect.Struct {
+		return nil, fmt.Errorf("(%v) is not a pointer to a struct", ptrType)
+	}
+
+	structType := ptrType.Elem()
+	var fields []Dependency
+	for i := 0; i < structType.NumField(); i++ {
+		field := structType.Field(i)
+		tag, ok := field.Tag.Lookup(tagName)
+		if !ok {
+			continue
+		}
+		if field.PkgPath != "" {
+			return nil, fmt.Errorf("field %s of (%v) is tagged for injection but is not exported", field.Name, structType)
+		}
+
+		dep := Dependency{Type: field.Type, Assignable: true, Multi: field.Type.Kind() == reflect.Slice, Field: field.Name}
+		for _, opt := range strings.Split(tag, ",") {
+			opt = strings.TrimSpace(opt)
+			switch {
+			case opt == "":
+			case opt == "optional":
+				dep.Optional = true
+			case strings.HasPrefix(opt, "name="):
+				dep.Name = strings.TrimPrefix(opt, "name=")
+			default:
+				return nil, fmt.Errorf("field %s of (%v) has an unknown %s tag option %q", field.Name, structType, tagName, opt)
+			}
+		}
+		fields = append(fields, dep)
+	}
+	return fields, nil
+}
@@ -0,0 +1,82 @@
+package test
+
+import (
+	"reflect"
+	"sync"
+	"sync/atomic"
+	"testing"
+
+	. "github.com/onsi/gomega"
+
+	"github.com/karlkfi/inject"
+)
+
+func TestConcurrentResolveConstructsOnce(t *testing.T) {
+	RegisterTestingT(t)
+
+	graph := inject.NewGraph()
+
+	var (
+		name = "FullName"
+		a    InterfaceA
+		b    InterfaceB
+	)
+
+	var aCount, bCount int32
+	graph.Define(&a, inject.NewAutoProvider(func(b InterfaceB) InterfaceA {
+		atomic.AddInt32(&aCount, 1)
+		return NewA(b)
+	}))
+	graph.Define(&b, inject.NewProvider(func(name string) InterfaceB {
+		atomic.AddInt32(&bCount, 1)
+		return NewB(name)
+	}, &name))
+
+	var wg sync.WaitGroup
+	values := make([]interface{}, 50)
+	for i := range values {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			if i%2 == 0 {
+				values[i] = graph.Resolve(&a).Interface()
+			} else {
+				values[i] = graph.ResolveByType(reflect.TypeOf(&b).Elem())[0].Elem().Interface()
+			}
+		}(i)
+	}
+	wg.Wait()
+
+	Expect(atomic.LoadInt32(&aCount)).To(Equal(int32(1)))
+	Expect(atomic.LoadInt32(&bCount)).To(Equal(int32(1)))
+	for i, value := range values {
+		if i%2 == 0 {
+			Expect(value).To(BeIdenticalTo(a))
+		} else {
+			Expect(value).To(BeIdenticalTo(b))
+		}
+	}
+}
+
+func TestConcurrentAddResolveFinalize(t *testing.T) {
+	RegisterTestingT(t)
+
+	graph := inject.NewGraph()
+
+	var wg sync.WaitGroup
+	for i := 0; i < 50; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			var l *lifecycleme
+			graph.Define(&l, inject.NewProvider(func() *lifecycleme { return &lifecycleme{} }))
+			graph.Resolve(&l)
+			graph.ResolveAll()
+			graph.Finalize()
+			_ = graph.String()
+		}()
+	}
+	wg.Wait()
+
+	Expect(graph.Validate()).To(Succeed())
+}
@@ -0,0 +1,149 @@
+package test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	. "github.com/onsi/gomega"
+
+	"github.com/karlkfi/inject"
+)
+
+type ctxKey struct{}
+
+func TestResolveContextInitializes(t *testing.T) {
+	RegisterTestingT(t)
+
+	graph := inject.NewGraph()
+
+	var (
+		c *contextme
+	)
+
+	graph.Define(&c, inject.NewProvider(func() *contextme { return &contextme{} }))
+
+	ctx := context.WithValue(context.Background(), ctxKey{}, "resolve")
+	_, err := graph.ResolveContext(ctx, &c)
+
+	Expect(err).ToNot(HaveOccurred())
+	Expect(c.initCtx.Value(ctxKey{})).To(Equal("resolve"))
+}
+
+func TestResolveContextInitializeError(t *testing.T) {
+	RegisterTestingT(t)
+
+	graph := inject.NewGraph()
+
+	var (
+		c *contextme
+	)
+
+	initErr := errors.New("port in use")
+	graph.Define(&c, inject.NewProvider(func() *contextme { return &contextme{initErr: initErr} }))
+
+	_, err := graph.ResolveContext(context.Background(), &c)
+
+	Expect(errors.Is(err, initErr)).To(BeTrue())
+	Expect(c).To(BeNil())
+}
+
+func TestResolveContextDeadline(t *testing.T) {
+	RegisterTestingT(t)
+
+	graph := inject.NewGraph()
+
+	var (
+		a InterfaceA
+		b InterfaceB
+		c InterfaceC
+	)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+
+	cCalled := false
+	graph.Define(&a, inject.NewProvider(func(b InterfaceB, c InterfaceC) InterfaceA { return NewA(b) }, &b, &c))
+	graph.Define(&b, inject.NewProvider(func() InterfaceB {
+		<-ctx.Done()
+		return NewB("slow")
+	}))
+	graph.Define(&c, inject.NewProvider(func() InterfaceC { cCalled = true; return NewC() }))
+
+	_, err := graph.ResolveContext(ctx, &a)
+
+	Expect(errors.Is(err, context.DeadlineExceeded)).To(BeTrue())
+	Expect(a).To(BeNil())
+	Expect(cCalled).To(BeFalse())
+}
+
+func TestResolveContextDeadlineRollback(t *testing.T) {
+	RegisterTestingT(t)
+
+	graph := inject.NewGraph()
+
+	var (
+		a InterfaceA
+		b InterfaceB
+		c *contextme
+		d *ImplD
+	)
+
+	ctx, cancel := context.WithTimeout(context.WithValue(context.Background(), ctxKey{}, "resolve"), 10*time.Millisecond)
+	defer cancel()
+
+	graph.Define(&a, inject.NewProvider(func(c *contextme, b InterfaceB, d *ImplD) InterfaceA { return NewA(b) }, &c, &b, &d))
+	graph.Define(&d, inject.NewProvider(NewD))
+	graph.Define(&b, inject.NewProvider(func() InterfaceB {
+		<-ctx.Done()
+		return NewB("slow")
+	}))
+	cc := &contextme{}
+	graph.Define(&c, inject.NewProvider(func() *contextme { return cc }))
+
+	_, err := graph.ResolveContext(ctx, &a)
+
+	Expect(errors.Is(err, context.DeadlineExceeded)).To(BeTrue())
+	Expect(c).To(BeNil())
+
+	// rolled back values are finalized with the values of the expired context, but without its deadline
+	Expect(cc.finalCtx).ToNot(BeNil())
+	Expect(cc.finalCtx.Err()).ToNot(HaveOccurred())
+	Expect(cc.finalCtx.Value(ctxKey{})).To(Equal("resolve"))
+}
+
+func TestFinalizeContextAggregatesErrors(t *testing.T) {
+	RegisterTestingT(t)
+
+	graph := inject.NewGraph()
+
+	var (
+		c1 *contextme
+		c2 *contextme
+		f  *finalme
+	)
+
+	err1 := errors.New("flush failed")
+	err2 := errors.New("close failed")
+	graph.Define(&c1, inject.NewProvider(func() *contextme { return &contextme{finalErr: err1} }))
+	graph.Define(&c2, inject.NewProvider(func() *contextme { return &contextme{finalErr: err2} }))
+	graph.Define(&f, inject.NewProvider(func() *finalme { return &finalme{} }))
+	graph.ResolveAll()
+
+	cc1, cc2, ff := c1, c2, f
+
+	ctx := context.WithValue(context.Background(), ctxKey{}, "finalize")
+	err := graph.FinalizeContext(ctx)
+
+	Expect(errors.Is(err, err1)).To(BeTrue())
+	Expect(errors.Is(err, err2)).To(BeTrue())
+
+	// every definition is obscured and finalized, despite errors
+	Expect(c1).To(BeNil())
+	Expect(c2).To(BeNil())
+	Expect(f).To(BeNil())
+	Expect(cc1.finalCtx.Value(ctxKey{})).To(Equal("finalize"))
+	Expect(cc2.finalCtx.Value(ctxKey{})).To(Equal("finalize"))
+	Expect(ff.finalized).To(BeTrue())
+}
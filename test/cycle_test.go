@@ -0,0 +1,153 @@
+package test
+
+import (
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	. "github.com/onsi/gomega"
+
+	"github.com/karlkfi/inject"
+)
+
+func TestResolveCycle(t *testing.T) {
+	RegisterTestingT(t)
+
+	graph := inject.NewGraph()
+
+	var (
+		a InterfaceA
+		b InterfaceB
+	)
+
+	graph.Define(&a, inject.NewProvider(NewA, &b))
+	graph.Define(&b, inject.NewAutoProvider(func(a InterfaceA) InterfaceB { return NewB(a.A()) }))
+
+	_, err := graph.TryResolve(&a)
+
+	var cycle inject.ErrCycle
+	Expect(errors.As(err, &cycle)).To(BeTrue())
+	Expect(cycle.Path).To(HaveLen(3))
+	Expect(cycle.Path[0].Ptr()).To(Equal(&a))
+	Expect(cycle.Path[1].Ptr()).To(Equal(&b))
+	Expect(cycle.Path[2].Ptr()).To(Equal(&a))
+	Expect(err.Error()).To(ContainSubstring(
+		"dependency cycle detected: " +
+			"*test.InterfaceA (func(test.InterfaceB) test.InterfaceA) -> " +
+			"*test.InterfaceB (func(test.InterfaceA) test.InterfaceB) -> " +
+			"*test.InterfaceA (func(test.InterfaceB) test.InterfaceA)",
+	))
+
+	Expect(a).To(BeNil())
+	Expect(b).To(BeNil())
+}
+
+func TestResolveSelfCycle(t *testing.T) {
+	RegisterTestingT(t)
+
+	graph := inject.NewGraph()
+
+	var (
+		b InterfaceB
+	)
+
+	graph.Define(&b, inject.NewAutoProvider(func(b InterfaceB) InterfaceB { return b }))
+
+	defer ExpectPanic("dependency cycle detected: *test.InterfaceB (func(test.InterfaceB) test.InterfaceB) -> *test.InterfaceB")
+	graph.Resolve(&b)
+}
+
+func TestResolveCycleConcurrently(t *testing.T) {
+	RegisterTestingT(t)
+
+	graph := inject.NewGraph()
+
+	var (
+		a        InterfaceA
+		b        InterfaceB
+		c        InterfaceC
+		name     string
+		bStarted = make(chan struct{})
+		once     sync.Once
+	)
+
+	// a depends on a slow c and then b, while b depends on a
+	graph.Define(&a, inject.NewAutoProvider(func(c InterfaceC, b InterfaceB) InterfaceA { return NewA(b) }))
+	graph.Define(&b, inject.NewAutoProvider(func(name string, a InterfaceA) InterfaceB { return NewB(name) }))
+	graph.Define(&c, inject.NewAutoProvider(func() InterfaceC {
+		<-bStarted
+		return NewC()
+	}))
+	graph.Define(&name, inject.NewAutoProvider(func() string {
+		// rolled back resolutions may be resolved again
+		once.Do(func() { close(bStarted) })
+		return "FullName"
+	}))
+
+	errs := make(chan error, 2)
+	go func() {
+		_, err := graph.TryResolve(&a)
+		errs <- err
+	}()
+	go func() {
+		_, err := graph.TryResolve(&b)
+		errs <- err
+	}()
+
+	for i := 0; i < 2; i++ {
+		select {
+		case err := <-errs:
+			var cycle inject.ErrCycle
+			Expect(errors.As(err, &cycle)).To(BeTrue())
+			Expect(cycle.Path[0]).To(Equal(cycle.Path[len(cycle.Path)-1]))
+		case <-time.After(time.Second):
+			t.Fatal("concurrent resolution of a cycle deadlocked")
+		}
+	}
+
+	Expect(a).To(BeNil())
+	Expect(b).To(BeNil())
+}
+
+func TestValidateCycle(t *testing.T) {
+	RegisterTestingT(t)
+
+	graph := inject.NewGraph()
+
+	var (
+		a InterfaceA
+		b InterfaceB
+	)
+
+	graph.Define(&a, inject.NewAutoProvider(NewA))
+	graph.Define(&b, inject.NewProvider(func(a InterfaceA) InterfaceB { return NewB(a.A()) }, &a))
+
+	err := graph.Validate()
+
+	var cycle inject.ErrCycle
+	Expect(errors.As(err, &cycle)).To(BeTrue())
+	Expect(cycle.Path).To(HaveLen(3))
+	Expect(cycle.Path[0]).To(Equal(cycle.Path[2]))
+}
+
+func TestValidateNoCycle(t *testing.T) {
+	RegisterTestingT(t)
+
+	graph := inject.NewGraph()
+
+	var (
+		name = "FullName"
+		a    InterfaceA
+		b    InterfaceB
+	)
+
+	graph.Define(&a, inject.NewAutoProvider(NewA))
+	graph.Define(&b, inject.NewProvider(NewB, &name))
+
+	Expect(graph.Validate()).To(Succeed())
+
+	// validation does not resolve anything
+	Expect(a).To(BeNil())
+	Expect(b).To(BeNil())
+}
@@ -0,0 +1,107 @@
+package test
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+
+	. "github.com/onsi/gomega"
+
+	"github.com/karlkfi/inject"
+)
+
+var typeB = reflect.TypeOf((*InterfaceB)(nil)).Elem()
+
+type tracedB struct {
+	InterfaceB
+	c           InterfaceC
+	initialized bool
+}
+
+func (t *tracedB) Initialize() {
+	t.initialized = true
+}
+
+func TestDecorate(t *testing.T) {
+	RegisterTestingT(t)
+
+	graph := inject.NewGraph()
+
+	var (
+		b1 InterfaceB
+		b2 InterfaceB
+		c  InterfaceC
+		a  InterfaceA
+	)
+
+	graph.Define(&b1, inject.NewProvider(func() InterfaceB { return NewB("b1") }))
+	graph.Define(&b2, inject.NewProvider(func() InterfaceB { return NewB("b2") }))
+	graph.Define(&c, inject.NewProvider(NewC))
+	graph.Define(&a, inject.NewProvider(NewA, &b1))
+
+	// the decorator's extra arguments are auto-resolved
+	graph.Decorate(typeB, func(b InterfaceB, c InterfaceC) InterfaceB {
+		return &tracedB{InterfaceB: b, c: c}
+	})
+
+	graph.Resolve(&a)
+
+	// decorated before being initialized and injected
+	Expect(b1).To(Equal(&tracedB{InterfaceB: NewB("b1"), c: NewC(), initialized: true}))
+	Expect(a).To(Equal(NewA(b1)))
+	Expect(b2).To(BeNil())
+
+	graph.Resolve(&b2)
+	Expect(b2).To(Equal(&tracedB{InterfaceB: NewB("b2"), c: NewC(), initialized: true}))
+}
+
+func TestDecorateOrder(t *testing.T) {
+	RegisterTestingT(t)
+
+	graph := inject.NewGraph()
+
+	var b InterfaceB
+	graph.Define(&b, inject.NewProvider(func() InterfaceB { return NewB("b") }), inject.WithLifetime(inject.Scoped))
+
+	graph.Decorate(typeB, func(b InterfaceB) InterfaceB { return NewB(b.B() + " parent") })
+
+	scope := graph.NewScope()
+	scope.Decorate(typeB, func(b InterfaceB) (InterfaceB, error) { return NewB(b.B() + " scope"), nil })
+
+	// parent decorators are applied first
+	Expect(scope.Resolve(&b).Interface()).To(Equal(NewB("B() -> B() -> b parent scope")))
+}
+
+func TestDecorateErrors(t *testing.T) {
+	RegisterTestingT(t)
+
+	graph := inject.NewGraph()
+
+	var b InterfaceB
+	graph.Define(&b, inject.NewProvider(func() InterfaceB { return NewB("b") }))
+
+	graph.Decorate(typeB, func(b InterfaceB, c InterfaceC) InterfaceB { return b })
+
+	err := graph.Validate()
+	Expect(err).To(HaveOccurred())
+	Expect(err.Error()).To(ContainSubstring("argument 1: decorator (func(test.InterfaceB, test.InterfaceC) test.InterfaceB): no defined pointer matches the specified type (test.InterfaceC)"))
+
+	_, err = graph.TryResolve(&b)
+	Expect(errors.As(err, &inject.ErrNoMatch{})).To(BeTrue())
+	Expect(err.Error()).To(ContainSubstring("failed to apply decorator"))
+	Expect(b).To(BeNil())
+}
+
+func TestDecorateInvalid(t *testing.T) {
+	RegisterTestingT(t)
+
+	graph := inject.NewGraph()
+
+	func() {
+		defer ExpectPanic("must take a value of the target type (test.InterfaceB) as its first argument")
+		graph.Decorate(typeB, func(c InterfaceC) InterfaceB { return nil })
+	}()
+
+	defer ExpectPanic("decorator return type (test.InterfaceC) must be assignable to the target type (test.InterfaceB)")
+	graph.Decorate(typeB, func(b InterfaceB) InterfaceC { return nil })
+}
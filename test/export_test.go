@@ -0,0 +1,101 @@
+package test
+
+import (
+	"bytes"
+	"encoding/json"
+	"testing"
+
+	. "github.com/onsi/gomega"
+
+	"github.com/karlkfi/inject"
+)
+
+func exportGraph() (inject.Graph, *InterfaceA) {
+	graph := inject.NewGraph()
+
+	var (
+		a InterfaceA
+		b InterfaceB
+		c InterfaceC
+		l *lazyA
+	)
+
+	graph.Define(&a, inject.NewProvider(NewA, &b))
+	graph.Define(&b, inject.NewProvider(func() InterfaceB { return NewB("b") }), inject.Named("primary"))
+	graph.Define(&c, inject.NewProvider(NewC))
+	graph.Define(&l, inject.NewAutoProvider(func(b inject.Lazy[InterfaceB]) *lazyA { return &lazyA{b: b} }))
+
+	return graph, &a
+}
+
+func TestWriteDOT(t *testing.T) {
+	RegisterTestingT(t)
+
+	graph, a := exportGraph()
+	graph.Resolve(a)
+
+	var out bytes.Buffer
+	Expect(inject.WriteDOT(&out, graph)).To(Succeed())
+	Expect(out.String()).To(Equal(`digraph inject {
+  node [shape=box];
+  n0 [label="*test.InterfaceA\nfunc(test.InterfaceB) test.InterfaceA", style=filled, fillcolor=palegreen];
+  n1 [label="*test.InterfaceB\nfunc() test.InterfaceB\nname: primary", style=filled, fillcolor=palegreen];
+  n2 [label="*test.InterfaceC\nfunc() test.InterfaceC"];
+  n3 [label="**test.lazyA\nfunc(inject.Lazy[github.com/karlkfi/inject/test.InterfaceB]) *test.lazyA"];
+  n0 -> n1;
+  n3 -> n1 [style=dashed];
+}
+`))
+}
+
+func TestWriteMermaid(t *testing.T) {
+	RegisterTestingT(t)
+
+	graph, a := exportGraph()
+	graph.Resolve(a)
+
+	var out bytes.Buffer
+	Expect(inject.WriteMermaid(&out, graph)).To(Succeed())
+	Expect(out.String()).To(Equal(`flowchart TD
+  n0["*test.InterfaceA<br/>func(test.InterfaceB) test.InterfaceA"]
+  n1["*test.InterfaceB<br/>func() test.InterfaceB<br/>name: primary"]
+  n2["*test.InterfaceC<br/>func() test.InterfaceC"]
+  n3["**test.lazyA<br/>func(inject.Lazy[github.com/karlkfi/inject/test.InterfaceB]) *test.lazyA"]
+  n0 --> n1
+  n3 -.-> n1
+  classDef resolved fill:#98fb98
+  class n0,n1 resolved
+`))
+}
+
+func TestDescribe(t *testing.T) {
+	RegisterTestingT(t)
+
+	graph, a := exportGraph()
+	graph.Resolve(a)
+
+	var s *service
+	graph.Define(&s, inject.NewStructProvider(&service{}), inject.Tagged("svc", "http"), inject.WithLifetime(inject.Transient))
+
+	out, err := json.MarshalIndent(graph.Describe(), "", "  ")
+	Expect(err).ToNot(HaveOccurred())
+	Expect(string(out)).To(MatchJSON(`{
+  "definitions": [
+    {"id": 0, "type": "test.InterfaceA", "provider": "provider", "signature": "func(test.InterfaceB) test.InterfaceA", "lifetime": "singleton", "resolved": true},
+    {"id": 1, "type": "test.InterfaceB", "provider": "provider", "signature": "func() test.InterfaceB", "lifetime": "singleton", "name": "primary", "resolved": true},
+    {"id": 2, "type": "test.InterfaceC", "provider": "provider", "signature": "func() test.InterfaceC", "lifetime": "singleton", "resolved": false},
+    {"id": 3, "type": "*test.lazyA", "provider": "auto", "signature": "func(inject.Lazy[github.com/karlkfi/inject/test.InterfaceB]) *test.lazyA", "lifetime": "singleton", "resolved": false},
+    {"id": 4, "type": "*test.service", "provider": "struct", "signature": "*test.service", "lifetime": "transient", "tags": ["svc", "http"], "resolved": false}
+  ],
+  "edges": [
+    {"from": 0, "to": 1},
+    {"from": 3, "to": 1, "lazy": true},
+    {"from": 4, "to": 1}
+  ]
+}`))
+
+	// the encoding is stable
+	again, err := json.MarshalIndent(graph.Describe(), "", "  ")
+	Expect(err).ToNot(HaveOccurred())
+	Expect(again).To(Equal(out))
+}
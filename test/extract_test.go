@@ -1,6 +1,7 @@
 package test
 
 import (
+	"reflect"
 	"testing"
 
 	. "github.com/onsi/gomega"
@@ -129,6 +130,65 @@ func TestExtractAssignableMultiMatch(t *testing.T) {
 	inject.ExtractAssignable(graph, &o)
 }
 
+func TestTryExtractByTypeNoMatch(t *testing.T) {
+	RegisterTestingT(t)
+
+	graph := inject.NewGraph()
+
+	var (
+		b1 *beta
+	)
+
+	graph.Define(&b1, inject.NewProvider(func() *beta { return &beta{name: "b1"} }))
+
+	var a *alpha
+	_, err := inject.TryExtractByType(graph, &a)
+
+	Expect(err).To(Equal(inject.ErrNoMatch{Type: reflect.TypeOf(a)}))
+	Expect(a).To(BeNil())
+}
+
+func TestTryExtractAssignableMultiMatch(t *testing.T) {
+	RegisterTestingT(t)
+
+	graph := inject.NewGraph()
+
+	var (
+		a1 *alpha
+		b1 *beta
+	)
+
+	graph.Define(&a1, inject.NewProvider(func() *alpha { return &alpha{name: "a1"} }))
+	graph.Define(&b1, inject.NewProvider(func() *beta { return &beta{name: "b1"} }))
+
+	var o omega
+	_, err := inject.TryExtractAssignable(graph, &o)
+
+	Expect(err).To(BeAssignableToTypeOf(inject.ErrAmbiguous{}))
+	ambiguous := err.(inject.ErrAmbiguous)
+	Expect(ambiguous.Assignable).To(BeTrue())
+	Expect(ambiguous.Candidates).To(HaveLen(2))
+
+	// ambiguous candidates are not resolved
+	Expect(a1).To(BeNil())
+	Expect(b1).To(BeNil())
+}
+
+func TestTryExtractByTypeNotPointer(t *testing.T) {
+	RegisterTestingT(t)
+
+	graph := inject.NewGraph()
+
+	var a alpha
+	_, err := inject.TryExtractByType(graph, a)
+
+	Expect(err).To(Equal(inject.ErrNotPointer{Type: reflect.TypeOf(a)}))
+}
+
 func ExpectPanic(content string) {
-	Expect(recover()).To(ContainSubstring(content))
+	r := recover()
+	if err, ok := r.(error); ok {
+		r = err.Error()
+	}
+	Expect(r).To(ContainSubstring(content))
 }
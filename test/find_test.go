@@ -55,3 +55,34 @@ func TestFindAssignable(t *testing.T) {
 	// alphas and betas (omegas), but not gammas
 	Expect(oList).To(ConsistOf(&alpha{name: "a1"}, &alpha{name: "a2"}, &beta{name: "b1"}))
 }
+
+func TestFindAssignableOrder(t *testing.T) {
+	RegisterTestingT(t)
+
+	graph := inject.NewGraph()
+
+	var (
+		b1 *beta
+		a1 *alpha
+		a2 *alpha
+		g1 *gamma
+	)
+
+	graph.Define(&b1, inject.NewProvider(func() *beta { return &beta{name: "b1"} }))
+	graph.Define(&a1, inject.NewProvider(func() *alpha { return &alpha{name: "a1"} }))
+	graph.Define(&a2, inject.NewProvider(func() *alpha { return &alpha{name: "a2"} }))
+	graph.Define(&g1, inject.NewProvider(func() *gamma { return &gamma{name: "g1"} }))
+
+	// overriding a pointer keeps its original position
+	graph.Override(inject.NewDefinition(&b1, inject.NewProvider(func() *beta { return &beta{name: "b2"} })))
+
+	scope := graph.NewScope()
+
+	var a3 *alpha
+	scope.Define(&a3, inject.NewProvider(func() *alpha { return &alpha{name: "a3"} }))
+
+	// inherited definitions come first, in the order they were defined
+	var oList []omega
+	inject.FindAssignable(scope, &oList)
+	Expect(oList).To(Equal([]omega{&beta{name: "b2"}, &alpha{name: "a1"}, &alpha{name: "a2"}, &alpha{name: "a3"}}))
+}
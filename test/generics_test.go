@@ -0,0 +1,110 @@
+package test
+
+import (
+	"errors"
+	"testing"
+
+	. "github.com/onsi/gomega"
+
+	"github.com/karlkfi/inject"
+)
+
+func TestGenericProvideAndGet(t *testing.T) {
+	RegisterTestingT(t)
+
+	graph := inject.NewGraph()
+
+	var (
+		name = "FullName"
+		b    InterfaceB
+	)
+
+	graph.Define(&b, inject.NewProvider(NewB, &name))
+	aPtr := inject.Provide[InterfaceA](graph, NewA)
+
+	a, err := inject.Get[InterfaceA](graph)
+
+	Expect(err).ToNot(HaveOccurred())
+	Expect(a).To(Equal(NewA(NewB(name))))
+	Expect(*aPtr).To(BeIdenticalTo(a))
+
+	Expect(inject.MustGet[InterfaceB](graph)).To(BeIdenticalTo(b))
+}
+
+func TestGenericProvideTyped(t *testing.T) {
+	RegisterTestingT(t)
+
+	graph := inject.NewGraph()
+
+	namePtr := inject.Provide0(graph, func() string { return "FullName" })
+	bPtr := inject.Provide1(graph, func(name string) InterfaceB { return NewB(name) })
+	aPtr := inject.Provide1(graph, NewA)
+	cPtr := inject.ProvideErr0(graph, func() (InterfaceC, error) { return NewC(), nil })
+	dPtr := inject.Provide2(graph, func(a InterfaceA, c InterfaceC) *ImplD { return NewD() })
+
+	Expect(inject.MustGet[*ImplD](graph)).To(Equal(NewD()))
+	Expect(*dPtr).To(Equal(NewD()))
+	Expect(*aPtr).To(Equal(NewA(NewB("FullName"))))
+	Expect(*bPtr).To(Equal(NewB("FullName")))
+	Expect(*cPtr).To(Equal(NewC()))
+	Expect(*namePtr).To(Equal("FullName"))
+}
+
+func TestGenericProvideTypedError(t *testing.T) {
+	RegisterTestingT(t)
+
+	graph := inject.NewGraph()
+
+	connErr := errors.New("connection refused")
+	inject.Provide0(graph, func() string { return "FullName" })
+	bPtr := inject.ProvideErr1(graph, func(name string) (InterfaceB, error) { return nil, connErr })
+
+	_, err := graph.TryResolve(bPtr)
+
+	Expect(errors.Is(err, connErr)).To(BeTrue())
+}
+
+func TestGenericGetNoMatch(t *testing.T) {
+	RegisterTestingT(t)
+
+	graph := inject.NewGraph()
+
+	c, err := inject.Get[InterfaceC](graph)
+
+	Expect(err).To(BeAssignableToTypeOf(inject.ErrNoMatch{}))
+	Expect(c).To(BeNil())
+}
+
+func TestGenericGetNamed(t *testing.T) {
+	RegisterTestingT(t)
+
+	graph := inject.NewGraph()
+
+	inject.Provide[InterfaceB](graph, func() InterfaceB { return NewB("primary") }, inject.Named("primary"))
+	inject.Provide[InterfaceB](graph, func() InterfaceB { return NewB("secondary") }, inject.Named("secondary"))
+
+	b, err := inject.GetNamed[InterfaceB](graph, "secondary")
+
+	Expect(err).ToNot(HaveOccurred())
+	Expect(b).To(Equal(NewB("secondary")))
+
+	_, err = inject.GetNamed[InterfaceC](graph, "secondary")
+
+	Expect(err).To(MatchError(ContainSubstring("cannot be assigned or converted")))
+}
+
+func TestGenericAll(t *testing.T) {
+	RegisterTestingT(t)
+
+	graph := inject.NewGraph()
+
+	inject.Provide[*alpha](graph, func() *alpha { return &alpha{name: "a1"} })
+	inject.Provide[*alpha](graph, func() *alpha { return &alpha{name: "a2"} })
+	inject.Provide[*beta](graph, func() *beta { return &beta{name: "b1"} })
+	inject.Provide[*gamma](graph, func() *gamma { return &gamma{name: "g1"} })
+
+	omegas := inject.All[omega](graph)
+
+	// alphas and betas (omegas), but not gammas
+	Expect(omegas).To(ConsistOf(&alpha{name: "a1"}, &alpha{name: "a2"}, &beta{name: "b1"}))
+}
@@ -1,7 +1,9 @@
 package test
 
 import (
+	"errors"
 	"fmt"
+	"reflect"
 	"testing"
 
 	. "github.com/onsi/gomega"
@@ -285,3 +287,192 @@ func TestGraphLifecycle(t *testing.T) {
   Expect(ff).To(Equal(&finalme{finalized: true}))
   Expect(ll).To(Equal(&lifecycleme{initialized: true, finalized: true}))
 }
+
+func TestGraphTryResolveAutoProviderNoMatch(t *testing.T) {
+	RegisterTestingT(t)
+
+	graph := inject.NewGraph()
+
+	var (
+		a InterfaceA
+	)
+
+	graph.Define(&a, inject.NewAutoProvider(NewA))
+
+	_, err := graph.TryResolve(&a)
+
+	Expect(err).To(MatchError(ContainSubstring("failed to resolve provider argument 0")))
+	Expect(errors.As(err, &inject.ErrNoMatch{})).To(BeTrue())
+	Expect(a).To(BeNil())
+}
+
+func TestGraphTryResolveNotPointer(t *testing.T) {
+	RegisterTestingT(t)
+
+	graph := inject.NewGraph()
+
+	_, err := graph.TryResolve("not a pointer")
+
+	Expect(err).To(Equal(inject.ErrNotPointer{Type: reflect.TypeOf("")}))
+}
+
+func TestGraphConstructorError(t *testing.T) {
+	RegisterTestingT(t)
+
+	graph := inject.NewGraph()
+
+	var (
+		fail = true
+		b    InterfaceB
+	)
+
+	graph.Define(&b, inject.NewAutoProvider(func() (InterfaceB, error) {
+		if fail {
+			return nil, errors.New("connection refused")
+		}
+		return NewB("FullName"), nil
+	}))
+
+	_, err := graph.TryResolve(&b)
+
+	Expect(err).To(MatchError(ContainSubstring("connection refused")))
+	Expect(b).To(BeNil())
+
+	func() {
+		defer ExpectPanic("connection refused")
+		graph.Resolve(&b)
+	}()
+
+	// failed definitions stay unresolved and can be retried
+	fail = false
+	graph.Resolve(&b)
+
+	Expect(b).To(Equal(NewB("FullName")))
+}
+
+func TestGraphConstructorErrorPropagates(t *testing.T) {
+	RegisterTestingT(t)
+
+	graph := inject.NewGraph()
+
+	var (
+		a InterfaceA
+		b InterfaceB
+	)
+
+	constructorErr := errors.New("invalid config")
+	graph.Define(&a, inject.NewProvider(NewA, &b))
+	graph.Define(&b, inject.NewProvider(func() (InterfaceB, error) { return nil, constructorErr }))
+
+	_, err := graph.TryResolve(&a)
+
+	Expect(errors.Is(err, constructorErr)).To(BeTrue())
+	Expect(a).To(BeNil())
+	Expect(b).To(BeNil())
+}
+
+func TestProviderRejectsInvalidReturnTypes(t *testing.T) {
+	RegisterTestingT(t)
+
+	defer ExpectPanic("constructor must return a value or a value and an error")
+	inject.NewProvider(func() (InterfaceB, string) { return nil, "" })
+}
+
+func TestGraphFinalizesInReverseResolutionOrder(t *testing.T) {
+	RegisterTestingT(t)
+
+	var log []string
+	newLogFinalizer := func(name string) func(deps ...*logFinalizer) *logFinalizer {
+		return func(deps ...*logFinalizer) *logFinalizer {
+			return &logFinalizer{name: name, log: &log}
+		}
+	}
+
+	var (
+		pool       *logFinalizer
+		repository *logFinalizer
+		cache      *logFinalizer
+		controller *logFinalizer
+	)
+
+	// definition order is not resolution order
+	graph := inject.NewGraph(
+		inject.NewDefinition(&controller, inject.NewProvider(newLogFinalizer("controller"), &repository, &cache)),
+		inject.NewDefinition(&cache, inject.NewProvider(newLogFinalizer("cache"))),
+		inject.NewDefinition(&repository, inject.NewProvider(newLogFinalizer("repository"), &pool)),
+		inject.NewDefinition(&pool, inject.NewProvider(newLogFinalizer("pool"))),
+	)
+
+	graph.Resolve(&controller)
+	graph.Finalize()
+
+	Expect(log).To(Equal([]string{"controller", "cache", "repository", "pool"}))
+
+	// resolution order is recorded again after finalization
+	log = nil
+	graph.Resolve(&cache)
+	graph.Resolve(&repository)
+	graph.Resolve(&controller)
+	graph.Finalize()
+
+	Expect(log).To(Equal([]string{"controller", "repository", "pool", "cache"}))
+}
+
+func TestGraphRejectsDuplicateDefinitions(t *testing.T) {
+	RegisterTestingT(t)
+
+	graph := inject.NewGraph()
+
+	var b InterfaceB
+	graph.Define(&b, inject.NewProvider(func() InterfaceB { return NewB("original") }))
+
+	err := graph.TryAdd(inject.NewDefinition(&b, inject.NewProvider(func() InterfaceB { return NewB("duplicate") })))
+	Expect(errors.As(err, &inject.ErrDuplicate{})).To(BeTrue())
+	Expect(err.Error()).To(ContainSubstring("is already defined by *test.InterfaceB (func() test.InterfaceB), use Override to replace it"))
+
+	defer ExpectPanic("is already defined")
+	graph.Define(&b, inject.NewProvider(func() InterfaceB { return NewB("duplicate") }))
+}
+
+func TestNewGraphRejectsDuplicateDefinitions(t *testing.T) {
+	RegisterTestingT(t)
+
+	var b InterfaceB
+
+	defer ExpectPanic("is already defined")
+	inject.NewGraph(
+		inject.NewDefinition(&b, inject.NewProvider(func() InterfaceB { return NewB("original") })),
+		inject.NewDefinition(&b, inject.NewProvider(func() InterfaceB { return NewB("duplicate") })),
+	)
+}
+
+func TestGraphOverride(t *testing.T) {
+	RegisterTestingT(t)
+
+	graph := inject.NewGraph()
+
+	var (
+		a InterfaceA
+		b InterfaceB
+	)
+
+	graph.Define(&a, inject.NewProvider(NewA, &b))
+	graph.Define(&b, inject.NewProvider(func() InterfaceB { return NewB("original") }))
+
+	graph.Override(inject.NewDefinition(&b, inject.NewProvider(func() InterfaceB { return NewB("mock") })))
+
+	graph.Resolve(&a)
+	Expect(a).To(Equal(NewA(NewB("mock"))))
+
+	// too late to override after resolution
+	err := graph.TryOverride(inject.NewDefinition(&b, inject.NewProvider(func() InterfaceB { return NewB("late") })))
+	Expect(errors.As(err, &inject.ErrOverrideResolved{})).To(BeTrue())
+	Expect(err.Error()).To(Equal("*test.InterfaceB (func() test.InterfaceB) cannot be overridden, because it has already been resolved"))
+	Expect(b).To(Equal(NewB("mock")))
+
+	// finalizing un-resolves the definitions, so they can be overridden again
+	graph.Finalize()
+	graph.Override(inject.NewDefinition(&b, inject.NewProvider(func() InterfaceB { return NewB("late") })))
+	graph.Resolve(&a)
+	Expect(a).To(Equal(NewA(NewB("late"))))
+}
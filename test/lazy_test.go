@@ -0,0 +1,170 @@
+package test
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	. "github.com/onsi/gomega"
+
+	"github.com/karlkfi/inject"
+)
+
+type lazyA struct {
+	b inject.Lazy[InterfaceB]
+}
+
+func TestLazyAutoProvider(t *testing.T) {
+	RegisterTestingT(t)
+
+	graph := inject.NewGraph()
+
+	var (
+		a     *lazyA
+		b     InterfaceB
+		calls int
+	)
+
+	graph.Define(&a, inject.NewAutoProvider(func(b inject.Lazy[InterfaceB]) *lazyA { return &lazyA{b: b} }))
+	graph.Define(&b, inject.NewProvider(func() InterfaceB {
+		calls++
+		return NewB("b")
+	}))
+
+	graph.Resolve(&a)
+	Expect(b).To(BeNil())
+
+	// resolved on first call, and cached afterwards
+	Expect(a.b()).To(Equal(NewB("b")))
+	Expect(a.b()).To(Equal(NewB("b")))
+	Expect(b).To(Equal(NewB("b")))
+	Expect(calls).To(Equal(1))
+}
+
+func TestLazyProviderError(t *testing.T) {
+	RegisterTestingT(t)
+
+	graph := inject.NewGraph()
+
+	var (
+		get func() (InterfaceB, error)
+		b   InterfaceB
+	)
+
+	graph.Define(&get, inject.NewProvider(func(b func() (InterfaceB, error)) func() (InterfaceB, error) { return b }, &b))
+	graph.Define(&b, inject.NewProvider(func() (InterfaceB, error) { return nil, errors.New("unavailable") }))
+
+	graph.Resolve(&get)
+
+	_, err := get()
+	Expect(err).To(MatchError(ContainSubstring("unavailable")))
+}
+
+type cyclicA struct {
+	b inject.Lazy[*cyclicB]
+}
+
+type cyclicB struct {
+	a *cyclicA
+}
+
+func TestLazyCyclicByDesign(t *testing.T) {
+	RegisterTestingT(t)
+
+	graph := inject.NewGraph()
+
+	var (
+		a *cyclicA
+		b *cyclicB
+	)
+
+	graph.Define(&a, inject.NewAutoProvider(func(b inject.Lazy[*cyclicB]) *cyclicA { return &cyclicA{b: b} }))
+	graph.Define(&b, inject.NewAutoProvider(func(a *cyclicA) *cyclicB { return &cyclicB{a: a} }))
+
+	Expect(graph.Validate()).To(BeNil())
+
+	graph.Resolve(&a)
+	Expect(a.b().a).To(BeIdenticalTo(a))
+}
+
+func TestLazyCycleDuringConstruction(t *testing.T) {
+	RegisterTestingT(t)
+
+	graph := inject.NewGraph()
+
+	var (
+		a *cyclicA
+		b *cyclicB
+	)
+
+	// calling a lazy argument from the constructor resolves it eagerly, so cycles are still detected
+	graph.Define(&a, inject.NewAutoProvider(func(b inject.Lazy[*cyclicB]) *cyclicA {
+		b()
+		return &cyclicA{b: b}
+	}))
+	graph.Define(&b, inject.NewAutoProvider(func(a *cyclicA) *cyclicB { return &cyclicB{a: a} }))
+
+	_, err := graph.TryResolve(&a)
+	Expect(err).To(MatchError(ContainSubstring("dependency cycle detected")))
+	Expect(errors.As(err, &inject.ErrCycle{})).To(BeTrue())
+}
+
+type initCyclicA struct {
+	b inject.Lazy[*initCyclicB]
+}
+
+func (a *initCyclicA) Initialize() {
+	a.b()
+}
+
+type initCyclicB struct {
+	a *initCyclicA
+}
+
+func TestLazyCycleDuringInitialization(t *testing.T) {
+	RegisterTestingT(t)
+
+	graph := inject.NewGraph()
+
+	var (
+		a *initCyclicA
+		b *initCyclicB
+	)
+
+	// calling a lazy argument from the initializer resolves it as part of the same resolution, so cycles are detected
+	graph.Define(&a, inject.NewAutoProvider(func(b inject.Lazy[*initCyclicB]) *initCyclicA { return &initCyclicA{b: b} }))
+	graph.Define(&b, inject.NewAutoProvider(func(a *initCyclicA) *initCyclicB { return &initCyclicB{a: a} }))
+
+	errs := make(chan error, 1)
+	go func() {
+		_, err := graph.TryResolve(&a)
+		errs <- err
+	}()
+
+	select {
+	case err := <-errs:
+		Expect(err).To(MatchError(ContainSubstring("dependency cycle detected")))
+		Expect(errors.As(err, &inject.ErrCycle{})).To(BeTrue())
+	case <-time.After(time.Second):
+		t.Fatal("lazy resolution from the initializer deadlocked")
+	}
+	Expect(a).To(BeNil())
+}
+
+func TestLazyDefinedFunction(t *testing.T) {
+	RegisterTestingT(t)
+
+	graph := inject.NewGraph()
+
+	var (
+		name    string
+		getName func() string
+	)
+
+	// a defined pointer to the function type takes precedence over a lazy argument
+	graph.Define(&name, inject.NewAutoProvider(func(get func() string) string { return get() }))
+	graph.Define(&getName, inject.NewProvider(func() func() string { return func() string { return "defined" } }))
+
+	graph.Resolve(&name)
+	Expect(name).To(Equal("defined"))
+}
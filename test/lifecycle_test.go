@@ -1,5 +1,9 @@
 package test
 
+import (
+	"context"
+)
+
 type initme struct {
 	initialized bool
 }
@@ -28,3 +32,29 @@ func (l *lifecycleme) Initialize() {
 func (l *lifecycleme) Finalize() {
 	l.finalized = true
 }
+
+type logFinalizer struct {
+	name string
+	log  *[]string
+}
+
+func (l *logFinalizer) Finalize() {
+	*l.log = append(*l.log, l.name)
+}
+
+type contextme struct {
+	initErr  error
+	finalErr error
+	initCtx  context.Context
+	finalCtx context.Context
+}
+
+func (c *contextme) Initialize(ctx context.Context) error {
+	c.initCtx = ctx
+	return c.initErr
+}
+
+func (c *contextme) Finalize(ctx context.Context) error {
+	c.finalCtx = ctx
+	return c.finalErr
+}
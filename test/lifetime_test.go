@@ -0,0 +1,129 @@
+package test
+
+import (
+	"fmt"
+	"testing"
+
+	. "github.com/onsi/gomega"
+
+	"github.com/karlkfi/inject"
+)
+
+func TestTransientLifetime(t *testing.T) {
+	RegisterTestingT(t)
+
+	graph := inject.NewGraph()
+
+	var (
+		a InterfaceA
+		b InterfaceB
+	)
+
+	count := 0
+	graph.Define(&a, inject.NewAutoProvider(NewA))
+	graph.Define(&b, inject.NewProvider(func() InterfaceB {
+		count++
+		return NewB(fmt.Sprintf("b%d", count))
+	}), inject.WithLifetime(inject.Transient))
+
+	b1 := graph.Resolve(&b).Interface()
+	b2 := graph.Resolve(&b).Interface()
+
+	Expect(count).To(Equal(2))
+	Expect(b1).To(Equal(NewB("b1")))
+	Expect(b2).To(Equal(NewB("b2")))
+
+	// singleton dependents are still only constructed once
+	graph.Resolve(&a)
+	graph.Resolve(&a)
+
+	Expect(count).To(Equal(3))
+	Expect(a).To(Equal(NewA(NewB("b3"))))
+}
+
+func TestTransientLifetimeFinalize(t *testing.T) {
+	RegisterTestingT(t)
+
+	var log []string
+
+	var (
+		single *logFinalizer
+		trans  *logFinalizer
+	)
+
+	count := 0
+	graph := inject.NewGraph(
+		inject.NewDefinition(&trans, inject.NewProvider(func() *logFinalizer {
+			count++
+			return &logFinalizer{name: fmt.Sprintf("transient%d", count), log: &log}
+		}), inject.WithLifetime(inject.Transient)),
+		inject.NewDefinition(&single, inject.NewProvider(func() *logFinalizer {
+			return &logFinalizer{name: "singleton", log: &log}
+		})),
+	)
+
+	graph.Resolve(&trans)
+	graph.Resolve(&single)
+	graph.Resolve(&trans)
+	graph.Resolve(&single)
+	graph.Finalize()
+
+	// every transient instance is finalized, in reverse order
+	Expect(log).To(Equal([]string{"transient2", "singleton", "transient1"}))
+	Expect(trans).To(BeNil())
+	Expect(single).To(BeNil())
+}
+
+func TestTransientLifetimeFinalizeInScope(t *testing.T) {
+	RegisterTestingT(t)
+
+	var log []string
+
+	var (
+		trans *logFinalizer
+	)
+
+	count := 0
+	graph := inject.NewGraph(
+		inject.NewDefinition(&trans, inject.NewProvider(func() *logFinalizer {
+			count++
+			return &logFinalizer{name: fmt.Sprintf("transient%d", count), log: &log}
+		}), inject.WithLifetime(inject.Transient)),
+	)
+
+	for i := 0; i < 2; i++ {
+		scope := graph.NewScope()
+		scope.Resolve(&trans)
+		scope.Finalize()
+	}
+
+	// transient instances resolved by a scope are finalized with the scope, not tracked by the root graph
+	Expect(log).To(Equal([]string{"transient1", "transient2"}))
+
+	log = nil
+	graph.Finalize()
+	Expect(log).To(BeEmpty())
+}
+
+func TestScopedLifetimeInRootGraph(t *testing.T) {
+	RegisterTestingT(t)
+
+	graph := inject.NewGraph()
+
+	var (
+		b InterfaceB
+	)
+
+	count := 0
+	def := graph.Define(&b, inject.NewProvider(func() InterfaceB {
+		count++
+		return NewB("b")
+	}), inject.WithLifetime(inject.Scoped))
+
+	graph.Resolve(&b)
+	graph.Resolve(&b)
+
+	// scoped definitions behave like singletons outside of a scope
+	Expect(count).To(Equal(1))
+	Expect(def.Lifetime()).To(Equal(inject.Scoped))
+}
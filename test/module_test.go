@@ -0,0 +1,221 @@
+package test
+
+import (
+	"errors"
+	"reflect"
+	"sync"
+	"testing"
+	"time"
+
+	. "github.com/onsi/gomega"
+
+	"github.com/karlkfi/inject"
+)
+
+var (
+	typeA = reflect.TypeOf((*InterfaceA)(nil)).Elem()
+	typeC = reflect.TypeOf((*InterfaceC)(nil)).Elem()
+)
+
+func TestModulePrivateDefinitions(t *testing.T) {
+	RegisterTestingT(t)
+
+	var (
+		a InterfaceA
+		b InterfaceB
+	)
+
+	mod := inject.NewModule("a",
+		inject.Include(
+			inject.NewDefinition(&a, inject.NewAutoProvider(NewA)),
+			inject.NewDefinition(&b, inject.NewProvider(func() InterfaceB { return NewB("private") })),
+		),
+		inject.Export(&a),
+	)
+
+	graph := inject.NewGraph()
+	graph.Install(mod)
+
+	Expect(graph.Validate()).To(BeNil())
+
+	// private definitions are hidden from lookups outside the module
+	Expect(graph.DefinitionsByAssignableType(typeB)).To(BeEmpty())
+	Expect(graph.ResolveByAssignableType(typeB)).To(BeEmpty())
+
+	// but are visible to the module's own auto-providers
+	Expect(inject.MustGet[InterfaceA](graph)).To(Equal(NewA(NewB("private"))))
+
+	// and can still be resolved by pointer
+	Expect(graph.Resolve(&b).Interface()).To(Equal(NewB("private")))
+}
+
+func TestModulePrivateDependencyOutsideModule(t *testing.T) {
+	RegisterTestingT(t)
+
+	var (
+		a InterfaceA
+		b InterfaceB
+	)
+
+	mod := inject.NewModule("b",
+		inject.Include(inject.NewDefinition(&b, inject.NewProvider(func() InterfaceB { return NewB("private") }))),
+		inject.ExportType(typeC),
+	)
+
+	graph := inject.NewGraph()
+	graph.Install(mod)
+	graph.Define(&a, inject.NewAutoProvider(NewA))
+
+	Expect(graph.Validate()).To(MatchError(ContainSubstring("no defined pointer matches the specified type (test.InterfaceB)")))
+
+	_, err := graph.TryResolve(&a)
+	Expect(errors.As(err, &inject.ErrNoMatch{})).To(BeTrue())
+}
+
+func TestModuleImports(t *testing.T) {
+	RegisterTestingT(t)
+
+	var (
+		a InterfaceA
+		b InterfaceB
+		c InterfaceC
+	)
+
+	bMod := inject.NewModule("b",
+		inject.Include(inject.NewDefinition(&b, inject.NewProvider(func() InterfaceB { return NewB("b") }))),
+	)
+	cMod := inject.NewModule("c",
+		inject.Include(inject.NewDefinition(&c, inject.NewProvider(NewC))),
+		inject.Import(bMod),
+	)
+	aMod := inject.NewModule("a",
+		inject.Include(inject.NewDefinition(&a, inject.NewAutoProvider(NewA))),
+		inject.Import(bMod, cMod),
+	)
+
+	graph := inject.NewGraph()
+
+	// duplicate installs are skipped, including in scopes
+	graph.Install(aMod)
+	graph.Install(bMod)
+	graph.NewScope().Install(cMod)
+
+	Expect(graph.Describe().Definitions).To(Equal([]inject.DefinitionDescription{
+		{ID: 0, Type: "test.InterfaceB", Provider: "provider", Signature: "func() test.InterfaceB", Lifetime: "singleton", Module: "b"},
+		{ID: 1, Type: "test.InterfaceC", Provider: "provider", Signature: "func() test.InterfaceC", Lifetime: "singleton", Module: "c"},
+		{ID: 2, Type: "test.InterfaceA", Provider: "auto", Signature: "func(test.InterfaceB) test.InterfaceA", Lifetime: "singleton", Module: "a"},
+	}))
+
+	graph.Resolve(&a)
+	Expect(a).To(Equal(NewA(NewB("b"))))
+}
+
+func TestModuleOverrideDefinitions(t *testing.T) {
+	RegisterTestingT(t)
+
+	var (
+		a InterfaceA
+		b InterfaceB
+	)
+
+	mod := inject.NewModule("a",
+		inject.Include(
+			inject.NewDefinition(&a, inject.NewAutoProvider(NewA)),
+			inject.NewDefinition(&b, inject.NewProvider(func() InterfaceB { return NewB("private") })),
+		),
+		inject.Export(&a),
+	)
+
+	graph := inject.NewGraph()
+	graph.Install(mod)
+
+	// overrides of module definitions stay in the module
+	graph.Override(inject.NewDefinition(&b, inject.NewProvider(func() InterfaceB { return NewB("mock") })))
+	graph.Override(inject.NewDefinition(&a, inject.NewAutoProvider(func(b InterfaceB) InterfaceA { return NewA(NewB(b.B())) })))
+
+	Expect(graph.Validate()).To(BeNil())
+	Expect(graph.DefinitionsByAssignableType(typeB)).To(BeEmpty())
+	Expect(graph.DefinitionsByAssignableType(typeA)).To(HaveLen(1))
+	Expect(inject.MustGet[InterfaceA](graph)).To(Equal(NewA(NewB("B() -> mock"))))
+}
+
+// slowModule takes a while to list its imports, so that concurrent installs overlap
+type slowModule struct {
+	inject.Module
+}
+
+func (m slowModule) Imports() []inject.Module {
+	time.Sleep(10 * time.Millisecond)
+	return m.Module.Imports()
+}
+
+func TestModuleInstallConcurrently(t *testing.T) {
+	RegisterTestingT(t)
+
+	var (
+		a InterfaceA
+		b InterfaceB
+	)
+
+	bMod := slowModule{inject.NewModule("b",
+		inject.Include(inject.NewDefinition(&b, inject.NewProvider(func() InterfaceB { return NewB("b") }))),
+	)}
+	aMod := slowModule{inject.NewModule("a",
+		inject.Include(inject.NewDefinition(&a, inject.NewAutoProvider(NewA))),
+		inject.Import(bMod),
+	)}
+
+	graph := inject.NewGraph()
+
+	var wg sync.WaitGroup
+	errs := make([]error, 4)
+	for i := range errs {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			errs[i] = graph.TryInstall(aMod)
+		}(i)
+	}
+	wg.Wait()
+
+	// every install succeeds, and the modules are installed once
+	for _, err := range errs {
+		Expect(err).ToNot(HaveOccurred())
+	}
+	Expect(graph.Describe().Definitions).To(HaveLen(2))
+	Expect(inject.MustGet[InterfaceA](graph)).To(Equal(NewA(NewB("b"))))
+}
+
+func TestModuleDuplicateDefinitions(t *testing.T) {
+	RegisterTestingT(t)
+
+	var (
+		b InterfaceB
+		c InterfaceC
+	)
+
+	graph := inject.NewGraph()
+	graph.Define(&b, inject.NewProvider(func() InterfaceB { return NewB("b") }))
+
+	mod := inject.NewModule("bc",
+		inject.Include(
+			inject.NewDefinition(&c, inject.NewProvider(NewC)),
+			inject.NewDefinition(&b, inject.NewProvider(func() InterfaceB { return NewB("duplicate") })),
+		),
+	)
+
+	// nothing is installed if any definition is a duplicate
+	err := graph.TryInstall(mod)
+	Expect(errors.As(err, &inject.ErrDuplicate{})).To(BeTrue())
+	Expect(err.Error()).To(HavePrefix(`failed to install module "bc": pointer (*test.InterfaceB=`))
+	Expect(graph.DefinitionsByType(typeC)).To(BeEmpty())
+}
+
+func TestModuleInvalidExport(t *testing.T) {
+	RegisterTestingT(t)
+
+	var b InterfaceB
+
+	defer ExpectPanic(`must be defined by module "empty"`)
+	inject.NewModule("empty", inject.Export(&b))
+}
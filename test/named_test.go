@@ -0,0 +1,155 @@
+package test
+
+import (
+	"errors"
+	"testing"
+
+	. "github.com/onsi/gomega"
+
+	"github.com/karlkfi/inject"
+)
+
+func TestResolveNamed(t *testing.T) {
+	RegisterTestingT(t)
+
+	graph := inject.NewGraph()
+
+	var (
+		primary   InterfaceB
+		secondary InterfaceB
+	)
+
+	graph.Define(&primary, inject.NewProvider(func() InterfaceB { return NewB("primary") }), inject.Named("primaryDB"))
+	graph.Define(&secondary, inject.NewProvider(func() InterfaceB { return NewB("secondary") }), inject.Named("secondaryDB"))
+
+	// consumers don't need access to the pointer
+	Expect(graph.ResolveNamed("secondaryDB").Interface()).To(Equal(NewB("secondary")))
+	Expect(secondary).To(Equal(NewB("secondary")))
+	Expect(primary).To(BeNil())
+
+	_, err := graph.TryResolveNamed("tertiaryDB")
+	Expect(err).To(Equal(inject.ErrNoMatch{Name: "tertiaryDB"}))
+}
+
+func TestResolveNamedScopeShadowing(t *testing.T) {
+	RegisterTestingT(t)
+
+	graph := inject.NewGraph()
+
+	var (
+		parent InterfaceB
+		child  InterfaceB
+	)
+
+	graph.Define(&parent, inject.NewProvider(func() InterfaceB { return NewB("parent") }), inject.Named("db"))
+
+	scope := graph.NewScope()
+	scope.Define(&child, inject.NewProvider(func() InterfaceB { return NewB("child") }), inject.Named("db"))
+
+	Expect(scope.ResolveNamed("db").Interface()).To(Equal(NewB("child")))
+	Expect(graph.ResolveNamed("db").Interface()).To(Equal(NewB("parent")))
+
+	var duplicate InterfaceB
+	graph.Define(&duplicate, inject.NewProvider(func() InterfaceB { return NewB("duplicate") }), inject.Named("db"))
+
+	_, err := graph.TryResolveNamed("db")
+	Expect(err).To(BeAssignableToTypeOf(inject.ErrAmbiguous{}))
+}
+
+func TestFindByTag(t *testing.T) {
+	RegisterTestingT(t)
+
+	graph := inject.NewGraph()
+
+	var (
+		a1 *alpha
+		a2 *alpha
+		b1 *beta
+		g1 *gamma
+	)
+
+	graph.Define(&a1, inject.NewProvider(func() *alpha { return &alpha{name: "a1"} }), inject.Tagged("http.handler"))
+	graph.Define(&a2, inject.NewProvider(func() *alpha { return &alpha{name: "a2"} }))
+	graph.Define(&b1, inject.NewProvider(func() *beta { return &beta{name: "b1"} }), inject.Tagged("http.handler", "admin"))
+	graph.Define(&g1, inject.NewProvider(func() *gamma { return &gamma{name: "g1"} }), inject.Tagged("http.handler"))
+
+	var handlers []omega
+	inject.FindByTag(graph, "http.handler", &handlers)
+
+	// tagged omegas, but not untagged alphas or gammas
+	Expect(handlers).To(ConsistOf(&alpha{name: "a1"}, &beta{name: "b1"}))
+	Expect(a2).To(BeNil())
+	Expect(g1).To(BeNil())
+
+	Expect(graph.ResolveByTag("http.handler")).To(HaveLen(3))
+	Expect(graph.DefinitionsByTag("admin")).To(HaveLen(1))
+}
+
+func TestFindByTagRollsBack(t *testing.T) {
+	RegisterTestingT(t)
+
+	graph := inject.NewGraph()
+
+	var (
+		a1 *alpha
+		b1 *beta
+	)
+
+	graph.Define(&a1, inject.NewProvider(func() *alpha { return &alpha{name: "a1"} }), inject.Tagged("http.handler"))
+	graph.Define(&b1, inject.NewProvider(func() (*beta, error) { return nil, errors.New("port in use") }), inject.Tagged("http.handler"))
+
+	var handlers []omega
+	_, err := inject.TryFindByTag(graph, "http.handler", &handlers)
+
+	Expect(err).To(MatchError(ContainSubstring("port in use")))
+	Expect(handlers).To(BeEmpty())
+
+	// matches resolved before the failure are rolled back
+	Expect(a1).To(BeNil())
+	Expect(graph.Describe().Definitions[0].Resolved).To(BeFalse())
+}
+
+func TestAutoProviderNamedArg(t *testing.T) {
+	RegisterTestingT(t)
+
+	graph := inject.NewGraph()
+
+	var (
+		a         InterfaceA
+		primary   InterfaceB
+		secondary InterfaceB
+	)
+
+	graph.Define(&a, inject.NewAutoProvider(NewA, inject.NamedArg(0, "secondaryDB")))
+	graph.Define(&primary, inject.NewProvider(func() InterfaceB { return NewB("primary") }), inject.Named("primaryDB"))
+	graph.Define(&secondary, inject.NewProvider(func() InterfaceB { return NewB("secondary") }), inject.Named("secondaryDB"))
+
+	Expect(graph.Validate()).To(Succeed())
+
+	graph.Resolve(&a)
+
+	Expect(a).To(Equal(NewA(NewB("secondary"))))
+	Expect(primary).To(BeNil())
+}
+
+func TestAutoProviderNamedArgValidation(t *testing.T) {
+	RegisterTestingT(t)
+
+	graph := inject.NewGraph()
+
+	var (
+		a InterfaceA
+		c InterfaceC
+	)
+
+	graph.Define(&a, inject.NewAutoProvider(NewA, inject.NamedArg(0, "c")))
+	graph.Define(&c, inject.NewProvider(NewC), inject.Named("c"))
+
+	err := graph.Validate()
+
+	var mismatch inject.ErrTypeMismatch
+	Expect(errors.As(err, &mismatch)).To(BeTrue())
+
+	_, err = graph.TryResolve(&a)
+	Expect(errors.As(err, &mismatch)).To(BeTrue())
+}
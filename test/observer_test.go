@@ -0,0 +1,169 @@
+package test
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"sync"
+	"strings"
+	"testing"
+	"time"
+
+	. "github.com/onsi/gomega"
+
+	"github.com/karlkfi/inject"
+)
+
+type eventLog struct {
+	mutex  sync.Mutex
+	events []string
+}
+
+func (l *eventLog) log(event string, def inject.Definition, err error) {
+	l.mutex.Lock()
+	defer l.mutex.Unlock()
+	entry := fmt.Sprintf("%s %v", event, reflect.TypeOf(def.Ptr()))
+	if err != nil {
+		entry += " failed"
+	}
+	l.events = append(l.events, entry)
+}
+
+func (l *eventLog) OnResolveStart(def inject.Definition) {
+	l.log("start", def, nil)
+}
+
+func (l *eventLog) OnResolveEnd(def inject.Definition, duration time.Duration, err error) {
+	l.log("end", def, err)
+}
+
+func (l *eventLog) OnInitialize(def inject.Definition, duration time.Duration, err error) {
+	l.log("initialize", def, err)
+}
+
+func (l *eventLog) OnFinalize(def inject.Definition, duration time.Duration, err error) {
+	l.log("finalize", def, err)
+}
+
+func TestObserver(t *testing.T) {
+	RegisterTestingT(t)
+
+	graph := inject.NewGraph()
+
+	observer := &eventLog{}
+	graph.Observe(observer)
+
+	var (
+		l *lifecycleme
+		i *initme
+	)
+
+	graph.Define(&l, inject.NewProvider(func(i *initme) *lifecycleme { return &lifecycleme{} }, &i))
+	graph.Define(&i, inject.NewProvider(func() *initme { return &initme{} }))
+
+	graph.Resolve(&l)
+	graph.Resolve(&l)
+	graph.Finalize()
+
+	Expect(observer.events).To(Equal([]string{
+		"start **test.lifecycleme",
+		"start **test.initme",
+		"initialize **test.initme",
+		"end **test.initme",
+		"initialize **test.lifecycleme",
+		"end **test.lifecycleme",
+		"finalize **test.lifecycleme",
+	}))
+}
+
+func TestObserverScope(t *testing.T) {
+	RegisterTestingT(t)
+
+	graph := inject.NewGraph()
+
+	observer := &eventLog{}
+	graph.Observe(observer)
+
+	var c *contextme
+	graph.Define(&c, inject.NewProvider(func() *contextme { return &contextme{initErr: errors.New("unavailable")} }), inject.WithLifetime(inject.Scoped))
+
+	// observers of a graph are notified of events in its scopes
+	_, err := graph.NewScope().TryResolve(&c)
+	Expect(err).To(HaveOccurred())
+
+	Expect(observer.events).To(Equal([]string{
+		"start **test.contextme",
+		"initialize **test.contextme failed",
+		"end **test.contextme failed",
+	}))
+}
+
+func TestRecorder(t *testing.T) {
+	RegisterTestingT(t)
+
+	graph := inject.NewGraph()
+
+	recorder := inject.NewRecorder()
+	graph.Observe(recorder)
+
+	var (
+		slow InterfaceA
+		b    InterfaceB
+	)
+
+	graph.Define(&slow, inject.NewProvider(func(b InterfaceB) InterfaceA {
+		time.Sleep(10 * time.Millisecond)
+		return NewA(b)
+	}, &b))
+	graph.Define(&b, inject.NewProvider(func() InterfaceB { return NewB("b") }), inject.WithLifetime(inject.Transient))
+
+	graph.Resolve(&slow)
+	graph.Resolve(&b)
+
+	timings := recorder.Timings()
+	Expect(timings).To(HaveLen(2))
+	Expect(timings[0].Definition.Ptr()).To(Equal(&slow))
+	Expect(timings[0].Count).To(Equal(1))
+	Expect(timings[0].Resolve).To(BeNumerically(">=", 10*time.Millisecond))
+	Expect(timings[1].Definition.Ptr()).To(Equal(&b))
+	Expect(timings[1].Count).To(Equal(2))
+
+	report := recorder.Report()
+	Expect(report).To(HavePrefix("RESOLVE"))
+	Expect(report).To(ContainSubstring("*test.InterfaceA (func(test.InterfaceB) test.InterfaceA)"))
+}
+
+func TestRecorderSelfTime(t *testing.T) {
+	RegisterTestingT(t)
+
+	graph := inject.NewGraph()
+
+	recorder := inject.NewRecorder()
+	graph.Observe(recorder)
+
+	var (
+		root *ImplD
+		slow InterfaceB
+	)
+
+	graph.Define(&root, inject.NewProvider(func(b InterfaceB) *ImplD { return NewD() }, &slow))
+	graph.Define(&slow, inject.NewProvider(func() InterfaceB {
+		time.Sleep(10 * time.Millisecond)
+		return NewB("slow")
+	}))
+
+	graph.Resolve(&root)
+
+	timings := recorder.Timings()
+	Expect(timings).To(HaveLen(2))
+	Expect(timings[0].Definition.Ptr()).To(Equal(&root))
+	Expect(timings[0].Resolve).To(BeNumerically(">=", 10*time.Millisecond))
+	Expect(timings[0].Self).To(BeNumerically("<", 10*time.Millisecond))
+	Expect(timings[1].Definition.Ptr()).To(Equal(&slow))
+	Expect(timings[1].Self).To(BeNumerically(">=", 10*time.Millisecond))
+
+	// the constructor responsible is reported first, not its dependent
+	lines := strings.Split(recorder.Report(), "\n")
+	Expect(lines[1]).To(ContainSubstring("*test.InterfaceB"))
+	Expect(lines[2]).To(ContainSubstring("*test.ImplD"))
+}
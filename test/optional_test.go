@@ -0,0 +1,91 @@
+package test
+
+import (
+	"errors"
+	"testing"
+
+	. "github.com/onsi/gomega"
+
+	"github.com/karlkfi/inject"
+)
+
+type reporter struct {
+	Sink  InterfaceB `inject:"optional"`
+	Label InterfaceC `inject:"name=label,optional"`
+}
+
+func TestOptionalArgMissing(t *testing.T) {
+	RegisterTestingT(t)
+
+	graph := inject.NewGraph()
+
+	var a InterfaceA
+
+	graph.Define(&a, inject.NewAutoProvider(func(b InterfaceB) InterfaceA {
+		return &implA{b: b}
+	}, inject.OptionalArg(0)))
+
+	Expect(graph.Validate()).To(BeNil())
+
+	graph.Resolve(&a)
+	Expect(a.(*implA).b).To(BeNil())
+}
+
+func TestOptionalArgPresent(t *testing.T) {
+	RegisterTestingT(t)
+
+	graph := inject.NewGraph()
+
+	var (
+		a InterfaceA
+		b InterfaceB
+	)
+
+	graph.Define(&a, inject.NewAutoProvider(NewA, inject.OptionalArg(0)))
+	graph.Define(&b, inject.NewProvider(func() InterfaceB { return NewB("b") }))
+
+	graph.Resolve(&a)
+	Expect(a).To(Equal(NewA(NewB("b"))))
+}
+
+func TestOptionalArgFailedDependency(t *testing.T) {
+	RegisterTestingT(t)
+
+	graph := inject.NewGraph()
+
+	var (
+		a InterfaceA
+		b InterfaceB
+	)
+
+	// a defined dependency that fails to resolve is an error, even if it is optional
+	graph.Define(&a, inject.NewAutoProvider(NewA, inject.OptionalArg(0)))
+	graph.Define(&b, inject.NewProvider(func() (InterfaceB, error) { return nil, errors.New("unavailable") }))
+
+	_, err := graph.TryResolve(&a)
+	Expect(err).To(MatchError(ContainSubstring("unavailable")))
+}
+
+func TestOptionalArgInvalidIndex(t *testing.T) {
+	RegisterTestingT(t)
+
+	defer ExpectPanic("optional argument index (1) must be less than the number of constructor arguments (1)")
+	inject.NewAutoProvider(NewA, inject.OptionalArg(1))
+}
+
+func TestOptionalFields(t *testing.T) {
+	RegisterTestingT(t)
+
+	graph := inject.NewGraph()
+
+	var r *reporter
+
+	// missing optional fields keep the template value
+	graph.Define(&r, inject.NewStructProvider(&reporter{Label: NewC()}))
+
+	Expect(graph.Validate()).To(BeNil())
+
+	graph.Resolve(&r)
+	Expect(r.Sink).To(BeNil())
+	Expect(r.Label).To(Equal(NewC()))
+}
@@ -0,0 +1,88 @@
+package test
+
+import (
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	. "github.com/onsi/gomega"
+
+	"github.com/karlkfi/inject"
+)
+
+func TestResolveAllParallel(t *testing.T) {
+	RegisterTestingT(t)
+
+	graph := inject.NewGraph()
+
+	var (
+		a  InterfaceA
+		b  InterfaceB
+		c  InterfaceC
+		d1 *ImplD
+		d2 *ImplD
+	)
+
+	// b, c, d1 & d2 are independent, so they each wait for the others to start
+	var started sync.WaitGroup
+	started.Add(4)
+	overlapped := int32(0)
+	slow := func() {
+		started.Done()
+		done := make(chan struct{})
+		go func() {
+			started.Wait()
+			close(done)
+		}()
+		select {
+		case <-done:
+			atomic.AddInt32(&overlapped, 1)
+		case <-time.After(time.Second):
+		}
+	}
+
+	var bCount int32
+	graph.Define(&a, inject.NewAutoProvider(NewA))
+	graph.Define(&b, inject.NewProvider(func() InterfaceB {
+		atomic.AddInt32(&bCount, 1)
+		slow()
+		return NewB("FullName")
+	}))
+	graph.Define(&c, inject.NewProvider(func() InterfaceC { slow(); return NewC() }))
+	graph.Define(&d1, inject.NewProvider(func() *ImplD { slow(); return NewD() }))
+	graph.Define(&d2, inject.NewProvider(func() *ImplD { slow(); return NewD() }))
+
+	values := graph.ResolveAllParallel(4)
+
+	Expect(values).To(HaveLen(5))
+	Expect(atomic.LoadInt32(&overlapped)).To(Equal(int32(4)))
+	Expect(atomic.LoadInt32(&bCount)).To(Equal(int32(1)))
+	Expect(a).To(Equal(NewA(NewB("FullName"))))
+	Expect(c).To(Equal(NewC()))
+	Expect(d1).To(Equal(NewD()))
+	Expect(d2).To(Equal(NewD()))
+}
+
+func TestResolveAllParallelCycle(t *testing.T) {
+	RegisterTestingT(t)
+
+	graph := inject.NewGraph()
+
+	var (
+		a InterfaceA
+		b InterfaceB
+		d *ImplD
+	)
+
+	graph.Define(&a, inject.NewAutoProvider(NewA))
+	graph.Define(&b, inject.NewAutoProvider(func(a InterfaceA) InterfaceB { return NewB(a.A()) }))
+	graph.Define(&d, inject.NewProvider(NewD))
+
+	_, err := graph.TryResolveAllParallel(2)
+
+	Expect(err).To(MatchError(ContainSubstring("dependency cycle detected")))
+
+	// failed resolution is rolled back
+	Expect(d).To(BeNil())
+}
@@ -0,0 +1,192 @@
+package test
+
+import (
+	"errors"
+	"testing"
+
+	. "github.com/onsi/gomega"
+
+	"github.com/karlkfi/inject"
+)
+
+func TestResolveRollsBackOnPanic(t *testing.T) {
+	RegisterTestingT(t)
+
+	var log []string
+	newLogFinalizer := func(name string) func(deps ...*logFinalizer) *logFinalizer {
+		return func(deps ...*logFinalizer) *logFinalizer {
+			return &logFinalizer{name: name, log: &log}
+		}
+	}
+
+	var (
+		pool       *logFinalizer
+		repository *logFinalizer
+		cache      *logFinalizer
+		controller *logFinalizer
+		shared     *logFinalizer
+	)
+
+	graph := inject.NewGraph(
+		inject.NewDefinition(&controller, inject.NewProvider(func(deps ...*logFinalizer) *logFinalizer {
+			panic("out of sockets")
+		}, &shared, &repository, &cache)),
+		inject.NewDefinition(&cache, inject.NewProvider(newLogFinalizer("cache"))),
+		inject.NewDefinition(&repository, inject.NewProvider(newLogFinalizer("repository"), &pool)),
+		inject.NewDefinition(&pool, inject.NewProvider(newLogFinalizer("pool"))),
+		inject.NewDefinition(&shared, inject.NewProvider(newLogFinalizer("shared"))),
+	)
+
+	// resolved before the failing call, so not rolled back
+	graph.Resolve(&shared)
+
+	_, err := graph.TryResolve(&controller)
+
+	var panicErr inject.ErrPanic
+	Expect(errors.As(err, &panicErr)).To(BeTrue())
+	Expect(panicErr.Value).To(Equal("out of sockets"))
+	Expect(panicErr.Definition.Ptr()).To(Equal(&controller))
+
+	// definitions resolved by the failed call are finalized in reverse order
+	Expect(log).To(Equal([]string{"cache", "repository", "pool"}))
+	Expect(controller).To(BeNil())
+	Expect(cache).To(BeNil())
+	Expect(repository).To(BeNil())
+	Expect(pool).To(BeNil())
+	Expect(shared).ToNot(BeNil())
+
+	// rolled back definitions are not finalized again
+	log = nil
+	graph.Finalize()
+
+	Expect(log).To(Equal([]string{"shared"}))
+}
+
+func TestResolveRollsBackOnInitializeError(t *testing.T) {
+	RegisterTestingT(t)
+
+	graph := inject.NewGraph()
+
+	var (
+		f *finalme
+		c *contextme
+	)
+
+	var created *finalme
+	initErr := errors.New("port in use")
+	graph.Define(&f, inject.NewProvider(func() *finalme { created = &finalme{}; return created }))
+	graph.Define(&c, inject.NewProvider(func(f *finalme) *contextme { return &contextme{initErr: initErr} }, &f))
+
+	func() {
+		defer ExpectPanic("port in use")
+		graph.Resolve(&c)
+	}()
+
+	// the dependency resolved by the failed call is rolled back
+	Expect(c).To(BeNil())
+	Expect(f).To(BeNil())
+	Expect(created.finalized).To(BeTrue())
+}
+
+type poolUser struct {
+	pool *logFinalizer
+}
+
+// resolveWhileRollingBack resolves a from one goroutine, and b from another after a's dependency (pool) is resolved,
+// before a's constructor panics, and returns the errors of both
+func resolveWhileRollingBack(graph inject.Graph, a, b interface{}, poolResolved, bResolved chan struct{}) (error, error) {
+	aErr := make(chan error, 1)
+	go func() {
+		_, err := graph.TryResolve(a)
+		aErr <- err
+	}()
+
+	<-poolResolved
+	_, bErr := graph.TryResolve(b)
+	close(bResolved)
+	return <-aErr, bErr
+}
+
+func TestResolveRollbackKeepsSharedDependencies(t *testing.T) {
+	RegisterTestingT(t)
+
+	var log []string
+
+	var (
+		pool *logFinalizer
+		a    InterfaceA
+		b    *poolUser
+	)
+
+	poolResolved := make(chan struct{})
+	bResolved := make(chan struct{})
+	count := 0
+	graph := inject.NewGraph(
+		inject.NewDefinition(&pool, inject.NewProvider(func() *logFinalizer {
+			count++
+			return &logFinalizer{name: "pool", log: &log}
+		})),
+		inject.NewDefinition(&a, inject.NewProvider(func(pool *logFinalizer) InterfaceA {
+			close(poolResolved)
+			<-bResolved
+			panic("out of sockets")
+		}, &pool)),
+		inject.NewDefinition(&b, inject.NewProvider(func(pool *logFinalizer) *poolUser { return &poolUser{pool: pool} }, &pool)),
+	)
+
+	aErr, bErr := resolveWhileRollingBack(graph, &a, &b, poolResolved, bResolved)
+
+	Expect(errors.As(aErr, &inject.ErrPanic{})).To(BeTrue())
+	Expect(bErr).ToNot(HaveOccurred())
+
+	// the pool was received by a resolution that succeeded, so it is not rolled back
+	Expect(log).To(BeEmpty())
+	Expect(pool).ToNot(BeNil())
+	Expect(b.pool).To(BeIdenticalTo(pool))
+	Expect(graph.Resolve(&pool).Interface()).To(BeIdenticalTo(pool))
+	Expect(count).To(Equal(1))
+
+	graph.Finalize()
+	Expect(log).To(Equal([]string{"pool"}))
+}
+
+func TestResolveRollbackReleasesSharedDependencies(t *testing.T) {
+	RegisterTestingT(t)
+
+	var log []string
+
+	var (
+		pool *logFinalizer
+		a    InterfaceA
+		b    *poolUser
+	)
+
+	poolResolved := make(chan struct{})
+	bResolved := make(chan struct{})
+	graph := inject.NewGraph(
+		inject.NewDefinition(&pool, inject.NewProvider(func() *logFinalizer {
+			return &logFinalizer{name: "pool", log: &log}
+		})),
+		inject.NewDefinition(&a, inject.NewProvider(func(pool *logFinalizer) InterfaceA {
+			close(poolResolved)
+			<-bResolved
+			panic("out of sockets")
+		}, &pool)),
+		inject.NewDefinition(&b, inject.NewProvider(func(pool *logFinalizer) (*poolUser, error) {
+			return nil, errors.New("pool exhausted")
+		}, &pool)),
+	)
+
+	aErr, bErr := resolveWhileRollingBack(graph, &a, &b, poolResolved, bResolved)
+
+	Expect(aErr).To(HaveOccurred())
+	Expect(bErr).To(HaveOccurred())
+
+	// once every resolution that received the pool has rolled back, the pool is rolled back once
+	Expect(log).To(Equal([]string{"pool"}))
+	Expect(pool).To(BeNil())
+
+	log = nil
+	graph.Finalize()
+	Expect(log).To(BeEmpty())
+}
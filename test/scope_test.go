@@ -0,0 +1,221 @@
+package test
+
+import (
+	"fmt"
+	"reflect"
+	"sync"
+	"sync/atomic"
+	"testing"
+
+	. "github.com/onsi/gomega"
+
+	"github.com/karlkfi/inject"
+)
+
+func TestScopeInheritsParentDefinitions(t *testing.T) {
+	RegisterTestingT(t)
+
+	var log []string
+
+	var (
+		pool    *logFinalizer
+		request *logFinalizer
+	)
+
+	poolCount := 0
+	graph := inject.NewGraph()
+	graph.Define(&pool, inject.NewProvider(func() *logFinalizer {
+		poolCount++
+		return &logFinalizer{name: "pool", log: &log}
+	}))
+
+	for i := 0; i < 2; i++ {
+		scope := graph.NewScope()
+		name := fmt.Sprintf("request%d", i)
+		scope.Define(&request, inject.NewProvider(func(pool *logFinalizer) *logFinalizer {
+			Expect(pool.name).To(Equal("pool"))
+			return &logFinalizer{name: name, log: &log}
+		}, &pool))
+
+		scope.Resolve(&request)
+		scope.Finalize()
+
+		// request-scoped objects are finalized with the scope, but their parent dependencies are not
+		Expect(request).To(BeNil())
+		Expect(pool).ToNot(BeNil())
+	}
+
+	Expect(poolCount).To(Equal(1))
+	Expect(log).To(Equal([]string{"request0", "request1"}))
+
+	// the request definition is local to the scopes
+	Expect(graph.DefinitionsByType(reflect.TypeOf(request))).To(HaveLen(1))
+
+	graph.Finalize()
+
+	Expect(log).To(Equal([]string{"request0", "request1", "pool"}))
+}
+
+func TestScopeShadowsParentDefinitions(t *testing.T) {
+	RegisterTestingT(t)
+
+	var (
+		name = "FullName"
+		a    InterfaceA
+		b    InterfaceB
+		c    InterfaceC
+	)
+
+	graph := inject.NewGraph()
+	graph.Define(&a, inject.NewAutoProvider(NewA))
+	graph.Define(&b, inject.NewProvider(NewB, &name))
+	graph.Define(&c, inject.NewProvider(NewC))
+
+	// the parent resolves first, so that the scope could clobber its pointer
+	Expect(graph.Resolve(&b).Interface()).To(Equal(NewB(name)))
+
+	scope := graph.NewScope()
+	var mock InterfaceB
+	scope.Define(&b, inject.NewProvider(func() InterfaceB { return NewB("Mock") }))
+	scope.Define(&mock, inject.NewProvider(func() InterfaceB { return NewB("Mock2") }))
+
+	Expect(scope.Resolve(&b).Interface()).To(Equal(NewB("Mock")))
+
+	// the shadowed parent definition is not visible from the scope
+	values := scope.ResolveByType(reflect.TypeOf(&b).Elem())
+	Expect(values).To(HaveLen(2))
+
+	// the parent is not affected by the scope, including its pointer, which the scope does not write
+	Expect(b).To(Equal(NewB(name)))
+	Expect(graph.ResolveByType(reflect.TypeOf(&b).Elem())).To(HaveLen(1))
+	Expect(graph.Resolve(&b).Interface()).To(Equal(NewB(name)))
+
+	scope.Finalize()
+	Expect(b).To(Equal(NewB(name)))
+	Expect(graph.Resolve(&b).Interface()).To(BeIdenticalTo(b))
+
+	// assignable lookups fall back to the parent
+	var cs []InterfaceC
+	inject.FindAssignable(scope, &cs)
+	Expect(cs).To(Equal([]InterfaceC{NewC()}))
+}
+
+func TestScopeInheritedTransient(t *testing.T) {
+	RegisterTestingT(t)
+
+	var log []string
+
+	var (
+		trans *logFinalizer
+	)
+
+	count := 0
+	graph := inject.NewGraph()
+	graph.Define(&trans, inject.NewProvider(func() *logFinalizer {
+		count++
+		return &logFinalizer{name: fmt.Sprintf("transient%d", count), log: &log}
+	}), inject.WithLifetime(inject.Transient))
+
+	parentValue := graph.Resolve(&trans).Interface()
+	Expect(trans).To(BeIdenticalTo(parentValue))
+
+	scope := graph.NewScope()
+	scopeValue := scope.Resolve(&trans).Interface()
+	Expect(scopeValue).ToNot(BeIdenticalTo(parentValue))
+
+	// the scope's instance is not written to the parent's pointer, so it does not outlive the scope
+	Expect(trans).To(BeIdenticalTo(parentValue))
+	scope.Finalize()
+	Expect(log).To(Equal([]string{"transient2"}))
+	Expect(trans).To(BeIdenticalTo(parentValue))
+
+	// nor is the instance of a transient definition that shadows the parent's
+	shadow := graph.NewScope()
+	shadow.Define(&trans, inject.NewProvider(func() *logFinalizer {
+		return &logFinalizer{name: "shadow", log: &log}
+	}), inject.WithLifetime(inject.Transient))
+	shadow.Resolve(&trans)
+	shadow.Finalize()
+	Expect(log).To(Equal([]string{"transient2", "shadow"}))
+	Expect(trans).To(BeIdenticalTo(parentValue))
+}
+
+func TestScopedLifetime(t *testing.T) {
+	RegisterTestingT(t)
+
+	var log []string
+
+	var (
+		user *logFinalizer
+	)
+
+	count := 0
+	graph := inject.NewGraph()
+	graph.Define(&user, inject.NewProvider(func() *logFinalizer {
+		count++
+		return &logFinalizer{name: fmt.Sprintf("user%d", count), log: &log}
+	}), inject.WithLifetime(inject.Scoped))
+
+	scope1 := graph.NewScope()
+	scope2 := graph.NewScope()
+
+	u1 := scope1.Resolve(&user).Interface()
+	Expect(scope1.Resolve(&user).Interface()).To(BeIdenticalTo(u1))
+
+	u2 := scope2.Resolve(&user).Interface()
+	Expect(scope2.Resolve(&user).Interface()).To(BeIdenticalTo(u2))
+
+	Expect(u1).ToNot(BeIdenticalTo(u2))
+	Expect(count).To(Equal(2))
+
+	scope2.Finalize()
+	Expect(log).To(Equal([]string{"user2"}))
+
+	scope1.Finalize()
+	Expect(log).To(Equal([]string{"user2", "user1"}))
+
+	// nothing was resolved by the parent
+	graph.Finalize()
+	Expect(log).To(Equal([]string{"user2", "user1"}))
+}
+
+func TestScopedLifetimeConcurrentScopes(t *testing.T) {
+	RegisterTestingT(t)
+
+	var (
+		user *logFinalizer
+	)
+
+	var count int32
+	graph := inject.NewGraph()
+	graph.Define(&user, inject.NewProvider(func() *logFinalizer {
+		n := atomic.AddInt32(&count, 1)
+		return &logFinalizer{name: fmt.Sprintf("user%d", n), log: &[]string{}}
+	}), inject.WithLifetime(inject.Scoped))
+
+	var wg sync.WaitGroup
+	values := make([]interface{}, 8)
+	again := make([]interface{}, 8)
+	for i := range values {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			scope := graph.NewScope()
+			values[i] = scope.Resolve(&user).Interface()
+			again[i] = scope.Resolve(&user).Interface()
+			scope.Finalize()
+		}(i)
+	}
+	wg.Wait()
+
+	Expect(atomic.LoadInt32(&count)).To(Equal(int32(8)))
+	for i, value := range values {
+		u := value.(*logFinalizer)
+		Expect(again[i]).To(BeIdenticalTo(u))
+		// each scope finalizes its own value
+		Expect(*u.log).To(Equal([]string{u.name}))
+	}
+
+	// the shared pointer is never written by scopes
+	Expect(user).To(BeNil())
+}
@@ -0,0 +1,140 @@
+package test
+
+import (
+	"errors"
+	"testing"
+
+	. "github.com/onsi/gomega"
+
+	"github.com/karlkfi/inject"
+)
+
+type service struct {
+	Label     string
+	B         InterfaceB `inject:""`
+	C         InterfaceC `inject:"name=primaryC"`
+	Unrelated InterfaceA
+}
+
+func TestStructProvider(t *testing.T) {
+	RegisterTestingT(t)
+
+	graph := inject.NewGraph()
+
+	var (
+		b   InterfaceB
+		c   InterfaceC
+		svc *service
+	)
+
+	graph.Define(&b, inject.NewProvider(func() InterfaceB { return NewB("b") }))
+	graph.Define(&c, inject.NewProvider(NewC), inject.Named("primaryC"))
+	graph.Define(&svc, inject.NewStructProvider(&service{Label: "svc"}))
+
+	graph.Resolve(&svc)
+
+	Expect(svc.Label).To(Equal("svc"))
+	Expect(svc.B).To(Equal(NewB("b")))
+	Expect(svc.C).To(Equal(NewC()))
+	Expect(svc.Unrelated).To(BeNil())
+	Expect(graph.Validate()).To(BeNil())
+}
+
+func TestStructProviderMissing(t *testing.T) {
+	RegisterTestingT(t)
+
+	graph := inject.NewGraph()
+
+	var (
+		c   InterfaceC
+		svc *service
+	)
+
+	graph.Define(&c, inject.NewProvider(NewC), inject.Named("primaryC"))
+	graph.Define(&svc, inject.NewStructProvider(&service{}))
+
+	_, err := graph.TryResolve(&svc)
+	Expect(errors.As(err, &inject.ErrNoMatch{})).To(BeTrue())
+	Expect(err.Error()).To(ContainSubstring("failed to resolve field B"))
+	Expect(err.Error()).To(ContainSubstring("no defined pointer is assignable to the specified type"))
+
+	err = graph.Validate()
+	Expect(err).To(HaveOccurred())
+	Expect(err.Error()).To(ContainSubstring("field B: no defined pointer is assignable to the specified type"))
+}
+
+func TestInjectFieldsAmbiguous(t *testing.T) {
+	RegisterTestingT(t)
+
+	graph := inject.NewGraph()
+
+	var (
+		a alpha
+		b beta
+	)
+
+	graph.Define(&a, inject.NewProvider(func() alpha { return alpha{name: "a"} }))
+	graph.Define(&b, inject.NewProvider(func() beta { return beta{name: "b"} }))
+
+	target := &struct {
+		Named omega `inject:""`
+	}{}
+
+	defer ExpectPanic("more than one defined pointer is assignable to the specified type")
+	inject.InjectFields(graph, target)
+}
+
+func TestInjectFields(t *testing.T) {
+	RegisterTestingT(t)
+
+	graph := inject.NewGraph()
+
+	var a alpha
+	graph.Define(&a, inject.NewProvider(func() alpha { return alpha{name: "a"} }))
+
+	target := &struct {
+		Named omega `inject:""`
+	}{}
+
+	inject.InjectFields(graph, target)
+	Expect(target.Named).To(Equal(alpha{name: "a"}))
+}
+
+func TestInjectFieldsRollsBack(t *testing.T) {
+	RegisterTestingT(t)
+
+	graph := inject.NewGraph()
+
+	var (
+		b InterfaceB
+		c InterfaceC
+	)
+
+	graph.Define(&b, inject.NewProvider(func() InterfaceB { return NewB("b") }))
+	graph.Define(&c, inject.NewProvider(func() (InterfaceC, error) { return nil, errors.New("unavailable") }), inject.Named("primaryC"))
+
+	target := &service{}
+	err := inject.TryInjectFields(graph, target)
+
+	Expect(err).To(MatchError(ContainSubstring("failed to resolve field C")))
+
+	// fields resolved before the failure are rolled back and left unassigned
+	Expect(b).To(BeNil())
+	Expect(target.B).To(BeNil())
+	Expect(graph.Describe().Definitions[0].Resolved).To(BeFalse())
+}
+
+func TestStructProviderInvalid(t *testing.T) {
+	RegisterTestingT(t)
+
+	Expect(inject.TryInjectFields(inject.NewGraph(), service{})).To(MatchError(ContainSubstring("is not a pointer to a struct")))
+
+	Expect(inject.TryInjectFields(inject.NewGraph(), &struct {
+		hidden InterfaceB `inject:""`
+	}{})).To(MatchError(ContainSubstring("field hidden of (struct { hidden test.InterfaceB \"inject:\\\"\\\"\" }) is tagged for injection but is not exported")))
+
+	defer ExpectPanic("unknown inject tag option \"bogus\"")
+	inject.NewStructProvider(&struct {
+		B InterfaceB `inject:"bogus"`
+	}{})
+}
@@ -0,0 +1,93 @@
+package test
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+
+	. "github.com/onsi/gomega"
+
+	"github.com/karlkfi/inject"
+)
+
+func TestValidateReportsAllErrors(t *testing.T) {
+	RegisterTestingT(t)
+
+	graph := inject.NewGraph()
+
+	var (
+		other InterfaceA
+		a     InterfaceA
+		b1    InterfaceB
+		b2    InterfaceB
+		c     InterfaceC
+		d     *ImplD
+	)
+
+	called := false
+	graph.Define(&a, inject.NewAutoProvider(func(b InterfaceB) InterfaceA { called = true; return NewA(b) }))
+	graph.Define(&b1, inject.NewProvider(func(c InterfaceC) InterfaceB { called = true; return NewB(c.C()) }, &other))
+	graph.Define(&b2, inject.NewAutoProvider(func(c InterfaceC) InterfaceB { called = true; return NewB(c.C()) }))
+	graph.Define(&d, inject.NewAutoProvider(func(c InterfaceC, a InterfaceA) *ImplD { called = true; return NewD() }))
+
+	err := graph.Validate()
+
+	var validation inject.ErrValidation
+	Expect(errors.As(err, &validation)).To(BeTrue())
+	Expect(validation.Errors).To(HaveLen(4))
+
+	// ambiguous dependency of a
+	var ambiguous inject.ErrAmbiguous
+	Expect(errors.As(err, &ambiguous)).To(BeTrue())
+	Expect(ambiguous.Type).To(Equal(reflect.TypeOf(&b1).Elem()))
+	Expect(ambiguous.Candidates).To(HaveLen(2))
+
+	// type-mismatched dependency of b1
+	var mismatch inject.ErrTypeMismatch
+	Expect(errors.As(err, &mismatch)).To(BeTrue())
+	Expect(mismatch).To(Equal(inject.ErrTypeMismatch{
+		From: reflect.TypeOf(&other).Elem(),
+		To:   reflect.TypeOf(&c).Elem(),
+	}))
+
+	// missing dependencies of b2 and d
+	Expect(err.Error()).To(ContainSubstring("*test.InterfaceB (func(test.InterfaceC) test.InterfaceB) argument 0: no defined pointer matches the specified type (test.InterfaceC)"))
+	Expect(err.Error()).To(ContainSubstring("**test.ImplD (func(test.InterfaceC, test.InterfaceA) *test.ImplD) argument 0: no defined pointer matches the specified type (test.InterfaceC)"))
+
+	// validation does not construct anything
+	Expect(called).To(BeFalse())
+	Expect(a).To(BeNil())
+	Expect(b1).To(BeNil())
+	Expect(b2).To(BeNil())
+	Expect(c).To(BeNil())
+	Expect(d).To(BeNil())
+}
+
+func TestValidateReportsErrorsAndCycles(t *testing.T) {
+	RegisterTestingT(t)
+
+	graph := inject.NewGraph()
+
+	var (
+		a InterfaceA
+		b InterfaceB
+		d *ImplD
+	)
+
+	graph.Define(&a, inject.NewAutoProvider(NewA))
+	graph.Define(&b, inject.NewAutoProvider(func(a InterfaceA) InterfaceB { return NewB(a.A()) }))
+	graph.Define(&d, inject.NewAutoProvider(func(c InterfaceC) *ImplD { return NewD() }))
+
+	err := graph.Validate()
+
+	var validation inject.ErrValidation
+	Expect(errors.As(err, &validation)).To(BeTrue())
+	Expect(validation.Errors).To(HaveLen(2))
+
+	var cycle inject.ErrCycle
+	Expect(errors.As(err, &cycle)).To(BeTrue())
+
+	var noMatch inject.ErrNoMatch
+	Expect(errors.As(err, &noMatch)).To(BeTrue())
+	Expect(noMatch.Type).To(Equal(reflect.TypeOf((*InterfaceC)(nil)).Elem()))
+}
@@ -0,0 +1,99 @@
+package test
+
+import (
+	"errors"
+	"testing"
+
+	. "github.com/onsi/gomega"
+
+	"github.com/karlkfi/inject"
+)
+
+func TestValueProvider(t *testing.T) {
+	RegisterTestingT(t)
+
+	graph := inject.NewGraph()
+
+	var (
+		a    InterfaceA
+		b    InterfaceB
+		name string
+		l    *lifecycleme
+	)
+
+	existing := &lifecycleme{}
+
+	graph.Define(&a, inject.NewAutoProvider(NewA))
+	graph.Define(&b, inject.NewValueProvider(NewB("existing")))
+	graph.Define(&name, inject.NewValueProvider("config"))
+	graph.Define(&l, inject.NewValueProvider(existing))
+
+	Expect(graph.Validate()).To(BeNil())
+
+	graph.ResolveAll()
+	Expect(a).To(Equal(NewA(NewB("existing"))))
+	Expect(name).To(Equal("config"))
+	Expect(l).To(BeIdenticalTo(existing))
+	Expect(existing.initialized).To(BeTrue())
+
+	Expect(graph.Describe().Definitions[1].Provider).To(Equal("value"))
+	Expect(graph.Describe().Definitions[1].Signature).To(Equal("test.InterfaceB"))
+}
+
+func TestFuncProvider(t *testing.T) {
+	RegisterTestingT(t)
+
+	graph := inject.NewGraph()
+
+	var (
+		a InterfaceA
+		b InterfaceB
+	)
+
+	graph.Define(&a, inject.NewFuncProvider(func(g inject.Graph) (InterfaceA, error) {
+		b, err := inject.Get[InterfaceB](g)
+		if err != nil {
+			return nil, err
+		}
+		return NewA(b), nil
+	}))
+	graph.Define(&b, inject.NewProvider(func() InterfaceB { return NewB("b") }))
+
+	graph.Resolve(&a)
+	Expect(a).To(Equal(NewA(NewB("b"))))
+
+	Expect(graph.Describe().Definitions[0].Provider).To(Equal("func"))
+	Expect(graph.Describe().Definitions[0].Signature).To(Equal("func(inject.Graph) (test.InterfaceA, error)"))
+}
+
+func TestFuncProviderError(t *testing.T) {
+	RegisterTestingT(t)
+
+	graph := inject.NewGraph()
+
+	var b InterfaceB
+
+	cause := errors.New("unavailable")
+	graph.Define(&b, inject.NewFuncProvider(func(inject.Graph) (InterfaceB, error) { return nil, cause }))
+
+	_, err := graph.TryResolve(&b)
+	Expect(errors.Is(err, cause)).To(BeTrue())
+	Expect(err.Error()).To(ContainSubstring("provider function (func(inject.Graph) (test.InterfaceB, error)) failed: unavailable"))
+}
+
+func TestFuncProviderCycle(t *testing.T) {
+	RegisterTestingT(t)
+
+	graph := inject.NewGraph()
+
+	var b InterfaceB
+
+	// dependencies resolved by the function are still checked for cycles
+	graph.Define(&b, inject.NewFuncProvider(func(g inject.Graph) (InterfaceB, error) {
+		_, err := g.TryResolve(&b)
+		return nil, err
+	}))
+
+	_, err := graph.TryResolve(&b)
+	Expect(err).To(MatchError(ContainSubstring("dependency cycle detected")))
+}
@@ -126,3 +126,104 @@ func TestNotVariadicAll(t *testing.T) {
 
 	Expect(container.GetInstalled()).To(Equal(",v1,v2,v3"))
 }
+
+func TestAutoVariadicNone(t *testing.T) {
+	RegisterTestingT(t)
+
+	graph := inject.NewGraph()
+
+	var container *variadic.VariadicContainer
+
+	graph.Define(&container, inject.NewAutoProvider(variadic.NewVariadicContainer))
+	graph.ResolveAll()
+
+	Expect(container.GetInstalled()).To(Equal(""))
+}
+
+func TestAutoVariadicAll(t *testing.T) {
+	RegisterTestingT(t)
+
+	graph := inject.NewGraph()
+
+	var (
+		container *variadic.VariadicContainer
+		v1        *variadic.V1
+		v2        *variadic.V2
+		v3        variadic.Item
+	)
+
+	graph.Define(&container, inject.NewAutoProvider(variadic.NewVariadicContainer))
+	graph.Define(&v1, inject.NewProvider(variadic.NewV1))
+	graph.Define(&v2, inject.NewProvider(variadic.NewV2))
+	graph.Define(&v3, inject.NewProvider(func() variadic.Item { return variadic.NewV3() }))
+	Expect(graph.Validate()).To(BeNil())
+
+	graph.Resolve(&container)
+
+	Expect(container.GetInstalled()).To(Equal(",v1,v2,v3"))
+}
+
+func TestAutoNotVariadicAll(t *testing.T) {
+	RegisterTestingT(t)
+
+	graph := inject.NewGraph()
+
+	var (
+		container *variadic.NotVariadicContainer
+		v1        *variadic.V1
+		v2        *variadic.V2
+	)
+
+	graph.Define(&container, inject.NewAutoProvider(variadic.NewNotVariadicContainer))
+	graph.Define(&v1, inject.NewProvider(variadic.NewV1))
+	graph.Define(&v2, inject.NewProvider(variadic.NewV2))
+
+	graph.Resolve(&container)
+
+	Expect(container.GetInstalled()).To(Equal(",v1,v2"))
+}
+
+func TestAutoNotVariadicDefinedSlice(t *testing.T) {
+	RegisterTestingT(t)
+
+	graph := inject.NewGraph()
+
+	var (
+		container *variadic.NotVariadicContainer
+		list      []variadic.Item
+		v2        *variadic.V2
+	)
+
+	// a defined pointer to the slice type itself takes precedence over collecting the items
+	graph.Define(&container, inject.NewAutoProvider(variadic.NewNotVariadicContainer))
+	graph.Define(&list, inject.NewProvider(func() []variadic.Item { return []variadic.Item{variadic.NewV1()} }))
+	graph.Define(&v2, inject.NewProvider(variadic.NewV2))
+
+	graph.Resolve(&container)
+
+	Expect(container.GetInstalled()).To(Equal(",v1"))
+}
+
+func TestAutoVariadicOrder(t *testing.T) {
+	RegisterTestingT(t)
+
+	for i := 0; i < 20; i++ {
+		graph := inject.NewGraph()
+
+		var (
+			container *variadic.VariadicContainer
+			v3        *variadic.V3
+			v1        *variadic.V1
+			v2        *variadic.V2
+		)
+
+		graph.Define(&v3, inject.NewProvider(variadic.NewV3))
+		graph.Define(&container, inject.NewAutoProvider(variadic.NewVariadicContainer))
+		graph.Define(&v1, inject.NewProvider(variadic.NewV1))
+		graph.Define(&v2, inject.NewProvider(variadic.NewV2))
+
+		// items are collected in the order they were defined
+		graph.Resolve(&container)
+		Expect(container.GetInstalled()).To(Equal(",v3,v1,v2"))
+	}
+}
@@ -0,0 +1,111 @@
+package inject
+
+import (
+	"context"
+	"errors"
+	"sync"
+)
+
+// transaction records the definitions newly resolved by a single top-level resolution,
+// so that they can be rolled back if the resolution fails.
+// It also records the values it received from other transactions that were still open, because a value received by
+// more than one transaction is only rolled back once every transaction that received it has rolled back.
+type transaction struct {
+	mutex    sync.Mutex
+	resolved []*resolution
+	state    txState
+}
+
+// txState describes whether a transaction is still open, or how it ended
+type txState int
+
+const (
+	txOpen txState = iota
+	txCommitted
+	txRolledBack
+)
+
+func (tx *transaction) record(r *resolution) {
+	tx.mutex.Lock()
+	defer tx.mutex.Unlock()
+	tx.resolved = append(tx.resolved, r)
+}
+
+func (tx *transaction) end(state txState) {
+	tx.mutex.Lock()
+	defer tx.mutex.Unlock()
+	tx.state = state
+}
+
+func (tx *transaction) ended(state txState) bool {
+	tx.mutex.Lock()
+	defer tx.mutex.Unlock()
+	return tx.state == state
+}
+
+// txOf returns the transaction of the graph's current resolution, if any
+func txOf(g Graph) *transaction {
+	if cg, ok := g.(*graph); ok {
+		return cg.tx
+	}
+	return nil
+}
+
+// transact calls fn with a copy of the graph that records newly resolved definitions in a transaction.
+// If fn fails, every definition resolved by fn is obscured (finalized) in reverse order before the error is returned.
+// Nested calls join the transaction of the top-level call.
+func (g *graph) transact(fn func(*graph) error) error {
+	if g.tx != nil {
+		return fn(g)
+	}
+
+	next := *g
+	next.tx = &transaction{}
+	err := fn(&next)
+	if err != nil {
+		return g.rollback(next.tx, err)
+	}
+	next.tx.end(txCommitted)
+	return nil
+}
+
+// transactional calls fn with a copy of the graph that records newly resolved definitions in a single transaction,
+// like transact, if the graph supports transactions
+func transactional(g Graph, fn func(Graph) error) error {
+	if cg, ok := g.(*graph); ok {
+		return cg.transact(func(tg *graph) error {
+			return fn(tg)
+		})
+	}
+	return fn(g)
+}
+
+// rollback obscures (finalizes) the definitions resolved by a failed transaction, in reverse order,
+// and returns the transaction error combined with any finalizer errors.
+// Definitions whose values were also received by another transaction that has not rolled back are kept.
+// The finalizers get the values of the resolution context, but not its cancellation, which may have caused the failure.
+func (g *graph) rollback(tx *transaction, err error) error {
+	tx.mutex.Lock()
+	resolved := tx.resolved
+	tx.mutex.Unlock()
+
+	fg := g.withContext(context.WithoutCancel(g.context()))
+	errs := []error{err}
+	var released []*resolution
+	for i := len(resolved) - 1; i >= 0; i-- {
+		kept, releaseErr := resolved[i].release(fg, tx)
+		if releaseErr != nil {
+			errs = append(errs, releaseErr)
+		}
+		if !kept {
+			released = append(released, resolved[i])
+		}
+	}
+	forgetResolved(released)
+	tx.end(txRolledBack)
+
+	if len(errs) == 1 {
+		return err
+	}
+	return errors.Join(errs...)
+}
@@ -0,0 +1,162 @@
+package inject
+
+import (
+	"fmt"
+	"reflect"
+)
+
+// Validate inspects the definitions and provider dependencies of the graph, without resolving them,
+// and returns an ErrValidation describing every missing, ambiguous, type-mismatched and cyclic dependency found.
+func (g *graph) Validate() error {
+	var errs []error
+	defs := g.snapshot()
+	visited := make(map[Definition]bool, len(defs))
+	for _, def := range defs {
+		errs = append(errs, g.validateDependencies(def)...)
+		errs = append(errs, g.validateCycles(def, nil, visited)...)
+	}
+	if len(errs) > 0 {
+		return ErrValidation{Errors: errs}
+	}
+	return nil
+}
+
+// validateDependencies checks that every dependency of a definition, and of its decorators,
+// can be resolved to a value of the right type
+func (g *graph) validateDependencies(def Definition) []error {
+	// a module's definitions may depend on its private definitions
+	g = g.within(g.moduleOf(def))
+
+	var errs []error
+	for i, dep := range dependencies(def.Provider()) {
+		if err := g.validateDependency(dep); err != nil {
+			errs = append(errs, ErrDependency{Definition: def, Index: i, Field: dep.Field, Err: err})
+		}
+	}
+	for _, d := range decoratorsOf(g, def) {
+		args := d.arguments()
+		for i := 1; i < len(args); i++ {
+			if err := g.validateDependency(args[i]); err != nil {
+				err = fmt.Errorf("decorator (%v): %w", reflect.TypeOf(d.fn), err)
+				errs = append(errs, ErrDependency{Definition: def, Index: i, Err: err})
+			}
+		}
+	}
+	return errs
+}
+
+// validateDependency checks that a dependency can be resolved to a value of the right type
+func (g *graph) validateDependency(dep Dependency) error {
+	if resolvesLazily(g, dep) {
+		dep = lazyDependency(dep)
+	}
+	if dep.Ptr != nil {
+		argType := reflect.TypeOf(dep.Ptr).Elem()
+		if !argType.AssignableTo(dep.Type) && !argType.ConvertibleTo(dep.Type) {
+			return ErrTypeMismatch{From: argType, To: dep.Type}
+		}
+		return nil
+	}
+	if collectsMulti(g, dep) {
+		return nil
+	}
+
+	defs := g.dependencyDefinitions(dep)
+	if len(defs) == 0 && dep.Optional {
+		return nil
+	}
+	if len(defs) > 1 {
+		return ErrAmbiguous{Type: dep.Type, Assignable: dep.Assignable, Name: dep.Name, Candidates: defs}
+	} else if len(defs) == 0 {
+		return ErrNoMatch{Type: dep.Type, Assignable: dep.Assignable, Name: dep.Name}
+	} else if dep.Name != "" {
+		argType := reflect.TypeOf(defs[0].Ptr()).Elem()
+		if !argType.AssignableTo(dep.Type) && !argType.ConvertibleTo(dep.Type) {
+			return ErrTypeMismatch{From: argType, To: dep.Type}
+		}
+	}
+	return nil
+}
+
+// validateCycles walks the dependencies of a definition depth first, returning an ErrCycle for each dependency
+// that leads back to a definition already in the path
+func (g *graph) validateCycles(def Definition, path []Definition, visited map[Definition]bool) []error {
+	if visited[def] {
+		return nil
+	}
+
+	var errs []error
+	path = append(path, def)
+	mg := g.within(g.moduleOf(def))
+	for _, dep := range mg.definitionDependencies(def) {
+		// lazy dependencies are resolved after construction, so they may be cyclic by design
+		if resolvesLazily(mg, dep) {
+			continue
+		}
+		for _, depDef := range mg.dependencyDefinitions(dep) {
+			if i := indexOf(path, depDef); i >= 0 {
+				errs = append(errs, ErrCycle{Path: append(append([]Definition{}, path[i:]...), depDef)})
+				continue
+			}
+			errs = append(errs, g.validateCycles(depDef, path, visited)...)
+		}
+	}
+	visited[def] = true
+	return errs
+}
+
+// dependencyDefinitions returns the definitions that may be resolved to supply a dependency
+func (g *graph) dependencyDefinitions(dep Dependency) []Definition {
+	if dep.Ptr != nil {
+		if def, found := g.definition(dep.Ptr); found {
+			return []Definition{def}
+		}
+		return nil
+	}
+	return candidates(g, dep)
+}
+
+// candidates returns the definitions that may be resolved to supply an auto-resolved dependency
+func candidates(g Graph, dep Dependency) []Definition {
+	if resolvesLazily(g, dep) {
+		return candidates(g, lazyDependency(dep))
+	}
+	if dep.Name != "" {
+		return g.DefinitionsByName(dep.Name)
+	}
+	if collectsMulti(g, dep) {
+		return g.DefinitionsByAssignableType(dep.Type.Elem())
+	}
+	if dep.Assignable {
+		return g.DefinitionsByAssignableType(dep.Type)
+	}
+	return g.DefinitionsByType(dep.Type)
+}
+
+// collectsMulti returns true if a dependency is supplied by every definition assignable to its element type
+func collectsMulti(g Graph, dep Dependency) bool {
+	return dep.Multi && dep.Name == "" && len(g.DefinitionsByType(dep.Type)) == 0
+}
+
+// definitionDependencies returns the dependencies of the provider and decorators of a definition
+func (g *graph) definitionDependencies(def Definition) []Dependency {
+	deps := append([]Dependency(nil), dependencies(def.Provider())...)
+	return append(deps, decoratorDependencies(g, def)...)
+}
+
+// dependencies returns the dependencies of a provider, or none if it does not describe them
+func dependencies(p Provider) []Dependency {
+	if d, ok := p.(Dependent); ok {
+		return d.Dependencies()
+	}
+	return nil
+}
+
+func indexOf(defs []Definition, def Definition) int {
+	for i, d := range defs {
+		if d == def {
+			return i
+		}
+	}
+	return -1
+}
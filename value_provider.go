@@ -0,0 +1,79 @@
+package inject
+
+import (
+	"fmt"
+	"reflect"
+)
+
+type valueProvider[T any] struct {
+	value T
+}
+
+// NewValueProvider specifies an already constructed value (ex: a parsed config), which is provided as is.
+// Like any other provided value, it is initialized and finalized by the graph if it is Initializable or Finalizable.
+func NewValueProvider[T any](value T) Provider {
+	return valueProvider[T]{value: value}
+}
+
+// Provide returns the value
+func (p valueProvider[T]) Provide(Graph) (reflect.Value, error) {
+	return reflect.ValueOf(&p.value).Elem(), nil
+}
+
+// Type returns the type of value to expect from Provide
+func (p valueProvider[T]) ReturnType() reflect.Type {
+	return reflect.TypeOf((*T)(nil)).Elem()
+}
+
+func (p valueProvider[T]) kind() string {
+	return "value"
+}
+
+// String returns a multiline string representation of the valueProvider
+func (p valueProvider[T]) String() string {
+	return fmt.Sprintf("&valueProvider{\n%s\n}",
+		indent(fmt.Sprintf("type: %s", p.ReturnType()), 1),
+	)
+}
+
+type funcProvider[T any] struct {
+	fn func(Graph) (T, error)
+}
+
+// NewFuncProvider specifies a hand-rolled factory function, which is called with the graph, so that it can resolve
+// its own dependencies. Dependencies resolved by the function are not known to Validate.
+func NewFuncProvider[T any](fn func(Graph) (T, error)) Provider {
+	if fn == nil {
+		panic("fn must not be nil")
+	}
+	return funcProvider[T]{fn: fn}
+}
+
+// Provide returns the result of calling the function with the dependency graph
+func (p funcProvider[T]) Provide(g Graph) (reflect.Value, error) {
+	value, err := p.fn(g)
+	if err != nil {
+		return reflect.Value{}, fmt.Errorf("provider function (%s) failed: %w", p.constructorType(), err)
+	}
+	return reflect.ValueOf(&value).Elem(), nil
+}
+
+// Type returns the type of value to expect from Provide
+func (p funcProvider[T]) ReturnType() reflect.Type {
+	return reflect.TypeOf((*T)(nil)).Elem()
+}
+
+func (p funcProvider[T]) constructorType() reflect.Type {
+	return reflect.TypeOf(p.fn)
+}
+
+func (p funcProvider[T]) kind() string {
+	return "func"
+}
+
+// String returns a multiline string representation of the funcProvider
+func (p funcProvider[T]) String() string {
+	return fmt.Sprintf("&funcProvider{\n%s\n}",
+		indent(fmt.Sprintf("fn: %s", p.constructorType()), 1),
+	)
+}